@@ -0,0 +1,189 @@
+package sqan
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// AssertionError is returned in strict mode when a value doesn't satisfy an assertion of its field's tag.
+type AssertionError struct {
+	Value interface{}
+	// Assertion is the tag option that failed, like "min=0"
+	Assertion string
+	Column    string
+	// Field is the path of the field from the root struct
+	Field string
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf(translate("column %q value %#v doesn't satisfy %s of field %s"), e.Column, e.Value, e.Assertion, e.Field)
+}
+
+// parseAssertions sets the assertions specified in the options of f's tag.
+func parseAssertions(f *field) error {
+	for _, bound := range []struct {
+		value *float64
+		set   *bool
+		name  string
+	}{{&f.min, &f.hasMin, "min"}, {&f.max, &f.hasMax, "max"}} {
+		s, ok := f.opts.Get(bound.name)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return codeErrorf(CodeInvalidTag, "invalid %s %q in field %s, it must be a number", bound.name, s, f.path)
+		}
+		*bound.value, *bound.set = n, true
+	}
+
+	_, f.nonEmpty = f.opts.Get("nonempty")
+	return nil
+}
+
+// validate checks that the values scanned into the fields of v satisfy their sizes and, in strict mode,
+// their assertions.
+func validate(v reflect.Value, columns []string, columnFields []*field, o *options) error {
+	if err := checkSizes(v, columns, columnFields); err != nil {
+		return err
+	}
+	if o.strict {
+		return checkAssertions(v, columns, columnFields)
+	}
+	return nil
+}
+
+// checkAssertions returns an *AssertionError if the value of a field scanned doesn't satisfy its assertions.
+//
+// Numbers must be within min and max, NULL values aren't checked. nonempty rejects zero values, like
+// empty strings and NULL.
+func checkAssertions(v reflect.Value, columns []string, columnFields []*field) error {
+	for i, f := range columnFields {
+		if f == nil || !(f.hasMin || f.hasMax || f.nonEmpty) {
+			continue
+		}
+
+		value := v.FieldByIndex(f.index)
+		fail := func(assertion string) error {
+			var got interface{}
+			if value := reflect.Indirect(value); value.IsValid() {
+				got = value.Interface()
+			}
+			return &AssertionError{Column: columns[i], Field: f.path, Assertion: assertion, Value: got}
+		}
+
+		if f.nonEmpty && (value.IsZero() || reflect.Indirect(value).IsZero()) {
+			return fail("nonempty")
+		}
+
+		value = reflect.Indirect(value)
+		var n float64
+		switch value.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(value.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(value.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = value.Float()
+		default:
+			continue
+		}
+
+		if f.hasMin && n < f.min {
+			return fail("min=" + strconv.FormatFloat(f.min, 'g', -1, 64))
+		}
+		if f.hasMax && n > f.max {
+			return fail("max=" + strconv.FormatFloat(f.max, 'g', -1, 64))
+		}
+	}
+	return nil
+}
+
+// checkColumns verifies in strict mode that the columns match what their fields expect.
+//
+// The database type of the columns must be the one set in the "dbtype" option of the fields' tag,
+// if the driver reports it, and all the fields must be populated except the optional ones.
+func checkColumns(rows *sql.Rows, columns []string, columnFields []*field, mapping map[string]*field) error {
+	// Not all drivers report the types
+	if types, err := rows.ColumnTypes(); err == nil {
+		for i, f := range columnFields {
+			if f == nil {
+				continue
+			}
+
+			dbType := types[i].DatabaseTypeName()
+			if expected, ok := f.opts.Get("dbtype"); ok && dbType != "" && !strings.EqualFold(dbType, expected) {
+				return codeErrorf(CodeDBType, "column %q has database type %s but field %s expects %s", columns[i], dbType, f.path, expected)
+			}
+		}
+	}
+
+	var unset []string
+	for _, f := range unsetFields(columnFields, mapping) {
+		if !isOptional(f, mapping) {
+			unset = append(unset, f.path)
+		}
+	}
+	if len(unset) > 0 {
+		return codeErrorf(CodeUnsetField, "fields %s aren't populated by any column", strings.Join(unset, ", "))
+	}
+
+	return nil
+}
+
+// checkNullable reports the fields that can't hold NULL whose columns the driver reports as nullable,
+// scanning a NULL into them fails. In strict mode the first one is returned as an error, otherwise they
+// are passed to the warning function.
+//
+// Nothing is reported with the NullZero and NullReject policies, as they handle the NULL values of those
+// fields.
+func checkNullable(rows *sql.Rows, columns []string, columnFields []*field, o *options) error {
+	if o.nulls == NullZero || o.nulls == NullReject {
+		return nil
+	}
+
+	// Not all drivers report the types
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil
+	}
+
+	for i, f := range columnFields {
+		if f == nil || acceptsNull(f.typ) {
+			continue
+		}
+		if nullable, ok := types[i].Nullable(); !ok || !nullable {
+			continue
+		}
+
+		err := codeErrorf(CodeNullable, "column %q is nullable but field %s of type %s can't hold NULL", columns[i], f.path, f.typ)
+		if o.strict {
+			return err
+		}
+		o.warn(err)
+	}
+
+	return nil
+}
+
+// acceptsNull returns whether a NULL can be scanned into a value of type t.
+func acceptsNull(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return reflect.PtrTo(t).Implements(_scannerInterface)
+}
+
+// isOptional returns whether f or one of its parents has the "optional" tag option.
+func isOptional(f *field, mapping map[string]*field) bool {
+	for _, p := range mapping {
+		if _, ok := p.opts.Get("optional"); ok && hasIndexPrefix(f.index, p.index) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package sqan
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAssertions(t *testing.T) {
+	type asserted struct {
+		Letter string `db:"letter,nonempty"`
+		Weight int    `db:"weight,min=0,max=150"`
+	}
+
+	cases := []struct {
+		desc      string
+		query     string
+		assertion string
+	}{
+		{
+			desc:  "Valid",
+			query: "SELECT letter, weight FROM tests WHERE weight <= 150",
+		},
+		{
+			desc:      "Max",
+			query:     "SELECT letter, weight FROM tests",
+			assertion: "max=150",
+		},
+		{
+			desc:      "Min",
+			query:     "SELECT letter, -1 AS weight FROM tests",
+			assertion: "min=0",
+		},
+		{
+			desc:      "Nonempty",
+			query:     "SELECT '' AS letter, weight FROM tests",
+			assertion: "nonempty",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []asserted
+			err = Rows(&got, rows, Strict())
+			if tc.assertion == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var assertionErr *AssertionError
+			if !errors.As(err, &assertionErr) {
+				t.Fatalf("Expected an *AssertionError, got %v", err)
+			}
+			if assertionErr.Assertion != tc.assertion {
+				t.Errorf("Expected assertion %q to fail, got %q", tc.assertion, assertionErr.Assertion)
+			}
+		})
+	}
+
+	t.Run("Not strict", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, weight FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []asserted
+		if err := Rows(&got, rows); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Poly", func(t *testing.T) {
+		types := map[string]func() interface{}{
+			"asserted": func() interface{} { return &asserted{} },
+		}
+		query := "SELECT letter, weight, 'asserted' AS kind FROM tests"
+
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Poly(rows, "kind", types, Strict())
+		var assertionErr *AssertionError
+		if !errors.As(err, &assertionErr) {
+			t.Fatalf("Expected an *AssertionError, got %v", err)
+		}
+		if assertionErr.Assertion != "max=150" {
+			t.Errorf("Expected assertion %q to fail, got %q", "max=150", assertionErr.Assertion)
+		}
+
+		rows, err = db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Poly(rows, "kind", types); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		rows, err := db.Query("SELECT 'c' AS op, NULL AS old_letter, NULL::integer AS old_weight, '' AS new_letter, 1 AS new_weight")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Changes[asserted](rows, Strict())
+		var assertionErr *AssertionError
+		if !errors.As(err, &assertionErr) {
+			t.Fatalf("Expected an *AssertionError, got %v", err)
+		}
+		if assertionErr.Column != "new_letter" || assertionErr.Assertion != "nonempty" {
+			t.Errorf("Expected assertion nonempty to fail on new_letter, got %q on %s", assertionErr.Assertion, assertionErr.Column)
+		}
+	})
+}
+
+func TestDBType(t *testing.T) {
+	cases := []struct {
+		dest  interface{}
+		desc  string
+		valid bool
+	}{
+		{
+			desc: "Match",
+			dest: &[]struct {
+				Weight int `db:"weight,dbtype=int4"`
+			}{},
+			valid: true,
+		},
+		{
+			desc: "Mismatch",
+			dest: &[]struct {
+				Weight int `db:"weight,dbtype=NUMERIC"`
+			}{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query("SELECT weight FROM tests")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = Rows(tc.dest, rows, Strict())
+			if tc.valid && err != nil {
+				t.Fatal(err)
+			}
+			if !tc.valid && ErrorCode(err) != CodeDBType {
+				t.Errorf("Expected a database type error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUnsetFields(t *testing.T) {
+	type typo struct {
+		Letter string
+		Weight int `db:"wieght"`
+	}
+	rows, err := db.Query("SELECT letter FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []typo
+	if err := Rows(&got, rows, Strict()); ErrorCode(err) != CodeUnsetField {
+		t.Errorf("Expected an unset field error, got %v", err)
+	}
+}
+
+func TestAcceptsNull(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{value: "", expected: false},
+		{value: 0, expected: false},
+		{value: Sub{}, expected: false},
+		{value: new(string), expected: true},
+		{value: []byte{}, expected: true},
+		{value: sql.NullString{}, expected: true},
+		{value: map[string]interface{}{}, expected: true},
+	}
+
+	for _, tc := range cases {
+		typ := reflect.TypeOf(tc.value)
+		if got := acceptsNull(typ); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", typ, tc.expected, got)
+		}
+	}
+}
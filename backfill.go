@@ -0,0 +1,168 @@
+package sqan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// BackfillSpec describes the table iterated by Backfill.
+type BackfillSpec struct {
+	// Cursor is the primary key value after which the iteration starts, nil starts from the
+	// beginning. It's used to resume a backfill from the cursor reported by Progress.
+	Cursor interface{}
+	// Retry is called, if not nil, when fetching a batch fails, with the error and the number of consecutive
+	// failures. If it returns true the batch is fetched again from the last cursor, so a backfill can
+	// survive the loss of the connection. It may sleep to wait before retrying. Errors returned by the
+	// function processing the batches aren't retried.
+	Retry func(err error, attempt int) bool
+	// Progress is called, if not nil, after each batch is processed with the number of rows
+	// processed so far and the cursor to resume the backfill from.
+	Progress func(rows int, cursor interface{})
+	// Table and PK are the names of the table and its primary key column. They are included
+	// in the queries as they are, so they must not come from user input.
+	Table string
+	PK    string
+	// Placeholder is the bind parameter of the cursor in the queries, "$1" (PostgreSQL) if it's not set.
+	// Use "?" for MySQL and SQLite.
+	Placeholder string
+	// BatchSize is the number of rows of each batch, 1000 if it's not set.
+	BatchSize int
+}
+
+// Backfill iterates over all the rows of a table in batches sorted by the primary key, calling fn
+// with each of them. It stops at the first error fn returns.
+//
+// Batches are fetched using keyset pagination ("WHERE pk > $1 ORDER BY pk LIMIT n"), which keeps
+// the cost of each query constant regardless of the progress. The primary key column must be mapped
+// by T, a struct or a pointer to one. Only the columns mapped by T are selected, so the table can have
+// others.
+//
+// The options apply to each batch separately, the stats collected are the ones of the last batch. Limit
+// makes the batches smaller, the next one starts after the last row processed. Sample isn't supported, the
+// rows must be processed in order to know where the next batch starts.
+//
+// The queries use LIMIT, supported by PostgreSQL, MySQL and SQLite among others but not by SQL Server,
+// and the placeholder set in the spec.
+func Backfill[T any](ctx context.Context, q Queryer, spec BackfillSpec, fn func(batch []T) error, opts ...Option) error {
+	opts = contextOptions(ctx, opts)
+	o := newOptions(opts)
+
+	if spec.Table == "" || spec.PK == "" {
+		return errorf("the table and primary key must be specified")
+	}
+	if spec.BatchSize == 0 {
+		spec.BatchSize = 1000
+	}
+	if spec.BatchSize < 0 {
+		return errorf("batch size must be positive, got %d", spec.BatchSize)
+	}
+	if o.sample != nil {
+		return errorf("backfills can't be sampled")
+	}
+
+	t := baseType(typeOf[T]())
+	if t.Kind() != reflect.Struct {
+		return codeErrorf(CodeInvalidDest, "type parameter must be a struct or a pointer to one, got %s", typeOf[T]())
+	}
+	mapping, err := typeMapping(t, o.naming)
+	if err != nil {
+		return err
+	}
+	pk, ok := mapping[spec.PK]
+	if !ok {
+		return codeErrorf(CodeUnmappedColumn, "couldn't find a field for primary key column %q", spec.PK)
+	}
+	if spec.Placeholder == "" {
+		spec.Placeholder = "$1"
+	}
+
+	columns := strings.Join(mappedColumns(mapping), ", ")
+	first := fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT %d", columns, spec.Table, spec.PK, spec.BatchSize)
+	next := fmt.Sprintf("SELECT %s FROM %s WHERE %s > %s ORDER BY %s LIMIT %d",
+		columns, spec.Table, spec.PK, spec.Placeholder, spec.PK, spec.BatchSize)
+	cursor := spec.Cursor
+	total := 0
+	for {
+		query, args := next, []interface{}{cursor}
+		if cursor == nil {
+			query, args = first, nil
+		}
+
+		batch, more, err := fetchBatch[T](ctx, q, opts, query, args)
+		for attempt := 1; err != nil; attempt++ {
+			if spec.Retry == nil || ctx.Err() != nil || !spec.Retry(err, attempt) {
+				return err
+			}
+			batch, more, err = fetchBatch[T](ctx, q, opts, query, args)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		last := reflect.ValueOf(&batch[len(batch)-1]).Elem()
+		if last.Kind() == reflect.Ptr {
+			last = last.Elem()
+		}
+		v, ok := fieldByIndex(last, pk.index)
+		if !ok {
+			return errorf("primary key field %s is nil", pk.path)
+		}
+		cursor = v.Interface()
+		total += len(batch)
+		if spec.Progress != nil {
+			spec.Progress(total, cursor)
+		}
+
+		if len(batch) < spec.BatchSize && !more {
+			return nil
+		}
+	}
+}
+
+// mappedColumns returns the columns of mapping in the order their fields are declared. Optional fields,
+// whose columns may not exist, and structs mapped field by field aren't included, even if their fields
+// weren't mapped, like those of ancestors or nested deeper than the maximum depth.
+func mappedColumns(mapping map[string]*field) []string {
+	columns := make([]string, 0, len(mapping))
+	for c, f := range mapping {
+		if !mappedByFields(f.typ) && !isOptional(f, mapping) {
+			columns = append(columns, c)
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return lessIndex(mapping[columns[i]].index, mapping[columns[j]].index)
+	})
+	return columns
+}
+
+// mappedByFields returns whether the fields of t, or of its base type, are mapped to columns instead of t.
+func mappedByFields(t reflect.Type) bool {
+	t = baseType(t)
+	return t.Kind() == reflect.Struct && t != _timeType && !reflect.PtrTo(t).Implements(_scannerInterface)
+}
+
+// fetchBatch executes a query and scans its rows, more reports whether the options stopped the scanning
+// before the end of the result. The options are applied to each batch separately.
+func fetchBatch[T any](ctx context.Context, q Queryer, opts []Option, query string, args []interface{}) (batch []T, more bool, err error) {
+	o := newOptions(opts)
+	defer o.done()
+
+	rows, err := queryContext(ctx, q, o.tag, query, args)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	batch, err = collect[T](rows, o)
+	if err != nil {
+		return nil, false, err
+	}
+	return batch, rows.Next(), nil
+}
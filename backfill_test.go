@@ -0,0 +1,180 @@
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	spec := BackfillSpec{Table: "tests", PK: "weight", BatchSize: 2}
+
+	var (
+		got      []Test
+		batches  int
+		progress int
+		cursor   interface{}
+	)
+	spec.Progress = func(rows int, c interface{}) {
+		progress, cursor = rows, c
+	}
+	err := Backfill(ctx, db, spec, func(batch []Test) error {
+		batches++
+		got = append(got, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Test{records[1], records[0], records[2]}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if batches != 2 || progress != 3 || cursor != 200 {
+		t.Errorf("Expected 2 batches, 3 rows and cursor 200, got %d, %d and %v", batches, progress, cursor)
+	}
+
+	t.Run("Resume", func(t *testing.T) {
+		spec.Cursor = 100
+		var got []Test
+		err := Backfill(ctx, db, spec, func(batch []Test) error {
+			got = append(got, batch...)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := records[2:]
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		// Limit applies to each batch, not to the whole backfill
+		spec := BackfillSpec{Table: "tests", PK: "weight", BatchSize: 2}
+		var got []Test
+		err := Backfill(ctx, db, spec, func(batch []Test) error {
+			got = append(got, batch...)
+			return nil
+		}, Limit(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != len(records) {
+			t.Errorf("Expected %d rows, got %d", len(records), len(got))
+		}
+	})
+
+	t.Run("LimitBelowBatchSize", func(t *testing.T) {
+		spec := BackfillSpec{Table: "tests", PK: "weight", BatchSize: 3}
+		var got []Test
+		batches := 0
+		err := Backfill(ctx, db, spec, func(batch []Test) error {
+			got = append(got, batch...)
+			batches++
+			return nil
+		}, Limit(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != len(records) || batches != len(records) {
+			t.Errorf("Expected %d rows in %d batches, got %d rows in %d batches", len(records), len(records), len(got), batches)
+		}
+	})
+
+	t.Run("Sample", func(t *testing.T) {
+		spec := BackfillSpec{Table: "tests", PK: "weight"}
+		err := Backfill(ctx, db, spec, func(batch []Test) error { return nil }, Sample(1, 1))
+		if err == nil {
+			t.Error("Expected an error sampling a backfill")
+		}
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		type row struct {
+			Node
+			ID      int
+			Sub     Sub
+			Created time.Time
+			Missing string `db:"missing,optional"`
+		}
+		mapping, err := typeMapping(reflect.TypeOf(row{}), naming{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"letter", "weight", "id", "exported", "created"}
+		if got := mappedColumns(mapping); !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Placeholder", func(t *testing.T) {
+		var queries []string
+		q := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			queries = append(queries, query)
+			return db.QueryContext(ctx, strings.Replace(query, "?", "$1", 1), args...)
+		})
+
+		spec := BackfillSpec{Table: "tests", PK: "weight", BatchSize: 2, Placeholder: "?"}
+		var got []Test
+		err := Backfill(ctx, q, spec, func(batch []Test) error {
+			got = append(got, batch...)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{
+			"SELECT letter, exported, weight, lower_case FROM tests ORDER BY weight LIMIT 2",
+			"SELECT letter, exported, weight, lower_case FROM tests WHERE weight > ? ORDER BY weight LIMIT 2",
+		}
+		if !reflect.DeepEqual(expected, queries) {
+			t.Errorf("Expected %q, got %q", expected, queries)
+		}
+		if len(got) != len(records) {
+			t.Errorf("Expected %d rows, got %d", len(records), len(got))
+		}
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		// Fail every other query
+		calls := 0
+		q := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			calls++
+			if calls%2 == 1 {
+				return nil, errors.New("connection lost")
+			}
+			return db.QueryContext(ctx, query, args...)
+		})
+
+		spec := BackfillSpec{Table: "tests", PK: "weight", BatchSize: 2}
+		spec.Retry = func(err error, attempt int) bool {
+			return attempt < 2
+		}
+		var got []Test
+		err := Backfill(ctx, q, spec, func(batch []Test) error {
+			got = append(got, batch...)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []Test{records[1], records[0], records[2]}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+// Change is a row change read from a change data capture table, with the row images before
+// and after it.
+type Change[T any] struct {
+	Before *T
+	After  *T
+	Op     string
+}
+
+// Changes scans Debezium-style rows into a slice of changes.
+//
+// The "op" column is scanned into Op, columns prefixed with "old_" into Before and columns
+// prefixed with "new_" into After, the rest of their names are mapped to T's fields as usual.
+// An image is left nil when all its columns are NULL, like the before image of an insert. Other columns
+// return an error, unless AllowUnknownColumns is used, which skips them.
+func Changes[T any](rows *sql.Rows, opts ...Option) ([]Change[T], error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, codeErrorf(CodeInvalidDest, "change type must be a struct")
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, ambiguous, err := mapType(t, o.naming)
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		opColumn = iota
+		beforeColumn
+		afterColumn
+		// otherColumn is a column without a prefix skipped with AllowUnknownColumns
+		otherColumn
+	)
+	kinds := make([]int, len(columns))
+	columnFields := make([]*field, len(columns))
+	// The fields of each image, to validate them separately
+	beforeFields, afterFields := make([]*field, len(columns)), make([]*field, len(columns))
+	hasOp := false
+	for i, c := range columns {
+		if o.normalize != nil {
+			c = o.normalize(c)
+		}
+
+		var name string
+		switch {
+		case c == "op":
+			kinds[i] = opColumn
+			hasOp = true
+			continue
+		case strings.HasPrefix(c, "old_"):
+			kinds[i], name = beforeColumn, strings.TrimPrefix(c, "old_")
+		case strings.HasPrefix(c, "new_"):
+			kinds[i], name = afterColumn, strings.TrimPrefix(c, "new_")
+		case o.allowUnknown:
+			kinds[i] = otherColumn
+			continue
+		default:
+			return nil, errorf("column %q must be \"op\" or have the \"old_\" or \"new_\" prefix", c)
+		}
+
+		f, ok := o.lookup(mapping, name)
+		if err := ambiguous[name]; !ok && err != nil {
+			return nil, err
+		}
+		if !ok && !o.allowUnknown {
+			return nil, codeErrorf(CodeUnmappedColumn, "couldn't find a field for column %q", c)
+		}
+		columnFields[i] = f
+		if kinds[i] == beforeColumn {
+			beforeFields[i] = f
+		} else {
+			afterFields[i] = f
+		}
+	}
+	if !hasOp {
+		return nil, errorf("missing \"op\" column")
+	}
+
+	var (
+		changes []Change[T]
+		used    int64
+	)
+	probes := make([]nullProbe, len(columns))
+	fields := make([]interface{}, len(columns))
+	for o.next(rows) {
+		var change Change[T]
+
+		// Scan the row once to find out which images are present
+		for i := range fields {
+			if kinds[i] == opColumn {
+				fields[i] = &change.Op
+				continue
+			}
+			fields[i] = &probes[i]
+		}
+		if err := rows.Scan(fields...); err != nil {
+			return nil, hookScanRow(err)
+		}
+
+		beforeNull, afterNull := true, true
+		for i, kind := range kinds {
+			switch kind {
+			case beforeColumn:
+				beforeNull = beforeNull && probes[i].null
+			case afterColumn:
+				afterNull = afterNull && probes[i].null
+			}
+		}
+
+		var before, after reflect.Value
+		if !beforeNull {
+			change.Before = new(T)
+			before = reflect.ValueOf(change.Before).Elem()
+		}
+		if !afterNull {
+			change.After = new(T)
+			after = reflect.ValueOf(change.After).Elem()
+		}
+
+		for i, kind := range kinds {
+			var v reflect.Value
+			switch kind {
+			case beforeColumn:
+				v = before
+			case afterColumn:
+				v = after
+			}
+			if !v.IsValid() || columnFields[i] == nil {
+				fields[i] = discard{}
+				continue
+			}
+			allocNilPointers(v, columnFields[i].index)
+			fields[i] = v.FieldByIndex(columnFields[i].index).Addr().Interface()
+		}
+		err := rows.Scan(fields...)
+		if err == nil && before.IsValid() {
+			err = validate(before, columns, beforeFields, o)
+		}
+		if err == nil && after.IsValid() {
+			err = validate(after, columns, afterFields, o)
+		}
+		if err := hookScanRow(err); err != nil {
+			return nil, err
+		}
+		if err := o.countBytes(&used, reflect.ValueOf(&change).Elem(), len(changes)+1); err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, o.err(rows)
+}
@@ -0,0 +1,83 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	type letter struct {
+		Letter string
+		Weight int
+	}
+	expected := []Change[letter]{
+		{Op: "c", After: &letter{Letter: "A", Weight: 100}},
+		{Op: "u", Before: &letter{Letter: "A", Weight: 100}, After: &letter{Letter: "B", Weight: 150}},
+		{Op: "d", Before: &letter{Letter: "B", Weight: 150}},
+	}
+	rows, err := db.Query(`SELECT * FROM (VALUES
+	('c', NULL, NULL, 'A', 100),
+	('u', 'A', 100, 'B', 150),
+	('d', 'B', 150, NULL, NULL)
+) AS changes (op, old_letter, old_weight, new_letter, new_weight)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Changes[letter](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestChangesErrors(t *testing.T) {
+	cases := []struct {
+		desc  string
+		query string
+	}{
+		{
+			desc:  "Missing op",
+			query: "SELECT letter AS new_letter FROM tests",
+		},
+		{
+			desc:  "Column without prefix",
+			query: "SELECT 'c' AS op, letter FROM tests",
+		},
+		{
+			desc:  "Unknown column",
+			query: "SELECT 'c' AS op, letter AS new_unknown FROM tests",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Changes[Test](rows); err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+		})
+	}
+
+	t.Run("Allow unknown columns", func(t *testing.T) {
+		rows, err := db.Query("SELECT 'c' AS op, weight AS lsn, letter AS new_letter, letter AS new_unknown FROM tests WHERE letter = 'A'")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := Changes[Test](rows, AllowUnknownColumns())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].Before != nil || got[0].After == nil || got[0].After.Letter != "A" {
+			t.Errorf("Expected an insert of A, got %+v", got)
+		}
+	})
+}
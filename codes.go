@@ -0,0 +1,86 @@
+package sqan
+
+import "errors"
+
+// Error codes identify the kind of the errors returned by the package, they are stable across versions
+// and translations. Use ErrorCode to get the code of an error.
+const (
+	// CodeUnmappedColumn is used when a column or key doesn't match any field.
+	CodeUnmappedColumn = "SQAN001"
+	// CodeNilDest is used when the destination is nil.
+	CodeNilDest = "SQAN002"
+	// CodeInvalidDest is used when the type of the destination isn't supported.
+	CodeInvalidDest = "SQAN003"
+	// CodeInvalidTag is used when the options of a field's tag are invalid.
+	CodeInvalidTag = "SQAN004"
+	// CodeColumnOrder is used when a column isn't in the position expected by CheckOrder.
+	CodeColumnOrder = "SQAN005"
+	// CodeNoColumns is used by ErrNoColumns.
+	CodeNoColumns = "SQAN006"
+	// CodeTooManyRows is used by ErrTooManyRows and LimitError.
+	CodeTooManyRows = "SQAN007"
+	// CodeSize is used by SizeError.
+	CodeSize = "SQAN008"
+	// CodeAssertion is used by AssertionError.
+	CodeAssertion = "SQAN009"
+	// CodeMaxBytes is used by MaxBytesError.
+	CodeMaxBytes = "SQAN010"
+	// CodeDBType is used when the database type of a column isn't the one expected by its field.
+	CodeDBType = "SQAN011"
+	// CodeUnsetField is used in strict mode when fields aren't populated by any column.
+	CodeUnsetField = "SQAN012"
+	// CodeNullable is used when a nullable column or a NULL value is scanned into a field that can't hold
+	// NULL, including NullIntoNonPointerError.
+	CodeNullable = "SQAN013"
+	// CodeDuplicateColumn is used when a column appears more than once with the DuplicateError policy.
+	CodeDuplicateColumn = "SQAN014"
+	// CodeAmbiguousColumn is used when a column of the result is named after several fields at the same depth.
+	CodeAmbiguousColumn = "SQAN015"
+)
+
+// ErrorCode returns the code of err or of the first error it wraps that has one. Errors that don't come
+// from the package, like the ones returned by drivers, have no code and return an empty string.
+func ErrorCode(err error) string {
+	var coded interface{ Code() string }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+
+	switch {
+	case errors.Is(err, ErrNoColumns):
+		return CodeNoColumns
+	case errors.Is(err, ErrTooManyRows):
+		return CodeTooManyRows
+	}
+	return ""
+}
+
+// codedError is an error with a code.
+type codedError struct {
+	err  error
+	code string
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+func (e *codedError) Code() string  { return e.code }
+
+// codeErrorf is like errorf but it returns an error with a code.
+func codeErrorf(code, format string, args ...interface{}) error {
+	return &codedError{code: code, err: errorf(format, args...)}
+}
+
+// Code returns CodeSize.
+func (e *SizeError) Code() string { return CodeSize }
+
+// Code returns CodeAssertion.
+func (e *AssertionError) Code() string { return CodeAssertion }
+
+// Code returns CodeMaxBytes.
+func (e *MaxBytesError) Code() string { return CodeMaxBytes }
+
+// Code returns CodeTooManyRows.
+func (e *LimitError) Code() string { return CodeTooManyRows }
+
+// Code returns CodeNullable.
+func (e *NullIntoNonPointerError) Code() string { return CodeNullable }
@@ -0,0 +1,67 @@
+package sqan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	rows, err := db.Query("SELECT letter AS unknown FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Test
+	unmapped := Rows(&got, rows)
+
+	cases := []struct {
+		err      error
+		desc     string
+		expected string
+	}{
+		{
+			desc:     "Unmapped column",
+			err:      unmapped,
+			expected: CodeUnmappedColumn,
+		},
+		{
+			desc:     "Unmapped key",
+			err:      DecodeNDJSON(strings.NewReader(`{"unknown": 1}`), &[]Test{}),
+			expected: CodeUnmappedColumn,
+		},
+		{
+			desc:     "Invalid dest",
+			err:      DecodeNDJSON(strings.NewReader(""), &Test{}),
+			expected: CodeInvalidDest,
+		},
+		{
+			desc:     "Nil dest",
+			err:      ApplyPatch((*Test)(nil), nil),
+			expected: CodeNilDest,
+		},
+		{
+			desc:     "Wrapped sentinel",
+			err:      fmt.Errorf("listing users: %w", ErrTooManyRows),
+			expected: CodeTooManyRows,
+		},
+		{
+			desc:     "Typed error",
+			err:      &SizeError{},
+			expected: CodeSize,
+		},
+		{
+			desc:     "Foreign error",
+			err:      errors.New("connection refused"),
+			expected: "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := ErrorCode(tc.err); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// All scans the rows into a slice of values of type T, like Rows does without declaring the slice first.
+func All[T any](rows *sql.Rows, opts ...Option) ([]T, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	return collect[T](rows, o)
+}
+
+// CollectMap scans the rows into a map of values of type T indexed by the key returned by key.
+// A key appearing more than once returns an error.
+func CollectMap[K comparable, T any](rows *sql.Rows, key func(T) K, opts ...Option) (map[K]T, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	s, err := newRowScanner("type parameter", typeOf[T](), rows, o)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	var used int64
+	result := make(map[K]T)
+	for i := 1; o.next(rows); i++ {
+		v, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.countBytes(&used, v, i); err != nil {
+			return nil, err
+		}
+
+		t := v.Interface().(T)
+		k := key(t)
+		if _, ok := result[k]; ok {
+			return nil, errorf("duplicate key %v", k)
+		}
+		result[k] = t
+	}
+
+	return result, o.err(rows)
+}
+
+// GroupBy scans the rows into slices of values of type T grouped by the key returned by key, in the
+// order of the result. It's useful to group the children of one-to-many joins.
+func GroupBy[K comparable, T any](rows *sql.Rows, key func(T) K, opts ...Option) (map[K][]T, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	s, err := newRowScanner("type parameter", typeOf[T](), rows, o)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	var used int64
+	result := make(map[K][]T)
+	for i := 1; o.next(rows); i++ {
+		v, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.countBytes(&used, v, i); err != nil {
+			return nil, err
+		}
+
+		t := v.Interface().(T)
+		k := key(t)
+		result[k] = append(result[k], t)
+	}
+
+	return result, o.err(rows)
+}
+
+// CollectMapPK is like CollectMap but the rows are indexed by the value of the field with the "pk" tag
+// option, for example `db:"id,pk"`. T must be a struct or a pointer to one with exactly one field
+// tagged as pk, of type K.
+func CollectMapPK[K comparable, T any](rows *sql.Rows, opts ...Option) (map[K]T, error) {
+	t := baseType(typeOf[T]())
+	if t.Kind() != reflect.Struct {
+		rows.Close()
+		return nil, codeErrorf(CodeInvalidDest, "type parameter must be a struct or a pointer to one, got %s", typeOf[T]())
+	}
+	pk, err := pkField(t, newOptions(opts).naming)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	if pk.typ != typeOf[K]() {
+		rows.Close()
+		return nil, errorf("primary key field %s is of type %s, not %s", pk.path, pk.typ, typeOf[K]())
+	}
+
+	return CollectMap(rows, func(v T) K {
+		value := reflect.ValueOf(&v).Elem()
+		if value.Kind() == reflect.Ptr {
+			value = value.Elem()
+		}
+		if f, ok := fieldByIndex(value, pk.index); ok {
+			return f.Interface().(K)
+		}
+		var zero K
+		return zero
+	}, opts...)
+}
+
+// pkField returns the field of t tagged with the "pk" option.
+func pkField(t reflect.Type, n naming) (*field, error) {
+	mapping, err := typeMapping(t, n)
+	if err != nil {
+		return nil, err
+	}
+
+	var pk *field
+	for _, f := range mapping {
+		if _, ok := f.opts.Get("pk"); !ok {
+			continue
+		}
+		if pk != nil {
+			a, b := pk, f
+			if lessIndex(b.index, a.index) {
+				a, b = b, a
+			}
+			return nil, errorf("%s has more than one primary key field: %s and %s", t, a.path, b.path)
+		}
+		pk = f
+	}
+	if pk == nil {
+		return nil, errorf("%s has no field tagged as primary key", t)
+	}
+	return pk, nil
+}
+
+// Distinct scans the rows into a slice of unique values of type T, in the order they are first seen.
+// Use DistinctBy for types that aren't comparable or to compare only some fields.
+func Distinct[T comparable](rows *sql.Rows, opts ...Option) ([]T, error) {
+	return DistinctBy(rows, func(v T) T { return v }, opts...)
+}
+
+// DistinctBy scans the rows into a slice of values of type T, skipping those whose key was already seen.
+// The first row with each key is kept.
+func DistinctBy[T any, K comparable](rows *sql.Rows, key func(T) K, opts ...Option) ([]T, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	s, err := newRowScanner("type parameter", typeOf[T](), rows, o)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	var (
+		result []T
+		used   int64
+	)
+	seen := make(map[K]struct{})
+	for i := 1; o.next(rows); i++ {
+		v, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		t := v.Interface().(T)
+		k := key(t)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		if err := o.countBytes(&used, v, i); err != nil {
+			return nil, err
+		}
+		seen[k] = struct{}{}
+		result = append(result, t)
+	}
+
+	return result, o.err(rows)
+}
+
+// One scans the only row of the result into a value of type T. It returns sql.ErrNoRows if the
+// result is empty and ErrTooManyRows if it has more than one row.
+func One[T any](rows *sql.Rows, opts ...Option) (T, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	var result T
+	s, err := newRowScanner("type parameter", typeOf[T](), rows, o)
+	if err != nil || s == nil {
+		return result, err
+	}
+
+	if !o.next(rows) {
+		if err := o.err(rows); err != nil {
+			return result, err
+		}
+		return result, sql.ErrNoRows
+	}
+
+	v, err := s.scan(rows)
+	if err != nil {
+		return result, err
+	}
+
+	// Limit doesn't apply to the check for a second row
+	if rows.Next() {
+		return result, ErrTooManyRows
+	}
+	if err := o.err(rows); err != nil {
+		return result, err
+	}
+
+	return v.Interface().(T), nil
+}
+
+// Scalar returns the value of the first column of the first row scanned into a T, for queries like
+// "SELECT count(*) FROM users". It returns sql.ErrNoRows if the result is empty.
+func Scalar[T any](rows *sql.Rows, opts ...Option) (T, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	var result T
+	columns, err := rows.Columns()
+	if err != nil {
+		return result, err
+	}
+	if len(columns) == 0 {
+		return result, o.noColumns()
+	}
+
+	if !o.next(rows) {
+		if err := o.err(rows); err != nil {
+			return result, err
+		}
+		return result, sql.ErrNoRows
+	}
+
+	fields := make([]interface{}, len(columns))
+	fields[0] = &result
+	for i := 1; i < len(fields); i++ {
+		fields[i] = discard{}
+	}
+	if err := hookScanRow(rows.Scan(fields...)); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Reduce scans each row into a T and folds it into an accumulator that starts with the value of seed,
+// without keeping the rows in memory. It's meant to compute summaries of large results client-side.
+func Reduce[T, A any](rows *sql.Rows, seed A, fn func(A, T) A, opts ...Option) (A, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	acc := seed
+	s, err := newRowScanner("type parameter", typeOf[T](), rows, o)
+	if err != nil || s == nil {
+		return acc, err
+	}
+
+	for o.next(rows) {
+		v, err := s.scan(rows)
+		if err != nil {
+			return acc, err
+		}
+		acc = fn(acc, v.Interface().(T))
+	}
+
+	return acc, o.err(rows)
+}
+
+// ForEachBatch scans the rows into batches of up to n values of type T and calls fn with each of them,
+// to feed bulk writes without keeping the whole result in memory. It stops at the first error fn returns.
+//
+// The batch slice is reused between calls, fn mustn't retain it after returning.
+func ForEachBatch[T any](rows *sql.Rows, n int, fn func(batch []T) error, opts ...Option) error {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	if n < 1 {
+		return errorf("batch size must be positive, got %d", n)
+	}
+
+	s, err := newRowScanner("type parameter", typeOf[T](), rows, o)
+	if err != nil || s == nil {
+		return err
+	}
+
+	batch := make([]T, 0, n)
+	for o.next(rows) {
+		v, err := s.scan(rows)
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, v.Interface().(T))
+		if len(batch) == n {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := o.err(rows); err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// Each scans the rows one at a time into dest and calls fn after each of them, so only one row is held
+// in memory. It stops at the first error fn returns.
+//
+// dest accepts the same types as Row, each row overwrites it completely.
+func Each(dest interface{}, rows *sql.Rows, fn func() error, opts ...Option) error {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	value, err := destValue(dest)
+	if err != nil {
+		return err
+	}
+
+	s, err := newRowScanner("dest", value.Type(), rows, o)
+	if err != nil || s == nil {
+		return err
+	}
+
+	for o.next(rows) {
+		v, err := s.scan(rows)
+		if err != nil {
+			return err
+		}
+		value.Set(v)
+
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	return o.err(rows)
+}
+
+// collect scans the rows into a slice of values of type T.
+func collect[T any](rows *sql.Rows, o *options) ([]T, error) {
+	s, err := newRowScanner("type parameter", typeOf[T](), rows, o)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	var (
+		result []T
+		used   int64
+	)
+	for i := 0; o.next(rows); i++ {
+		slot := o.slot(i)
+		if slot == -1 {
+			continue
+		}
+
+		v, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.countBytes(&used, v, i+1); err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = make([]T, 0, max(o.capacity(), 1))
+		}
+		if slot < len(result) {
+			result[slot] = v.Interface().(T)
+		} else {
+			result = append(result, v.Interface().(T))
+		}
+	}
+
+	return result, o.err(rows)
+}
+
+// typeOf returns the type of T, it works with interfaces as well.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
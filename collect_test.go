@@ -0,0 +1,270 @@
+package sqan
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := All[Test](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records, got) {
+		t.Errorf("Expected %v, got %v", records, got)
+	}
+}
+
+func TestCollectMap(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := CollectMap(rows, func(t Test) string {
+		return t.Letter
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Test{"A": records[0], "b": records[1], "C": records[2]}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GroupBy(rows, func(t Test) bool {
+		return t.Lowercase
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[bool][]Test{
+		false: {records[0], records[2]},
+		true:  {records[1]},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCollectMapPK(t *testing.T) {
+	type letter struct {
+		Letter string `db:"letter,pk"`
+		Weight int
+	}
+	rows, err := db.Query("SELECT letter, weight FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := CollectMapPK[string, *letter](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]*letter{
+		"A": {Letter: "A", Weight: 100},
+		"b": {Letter: "b", Weight: 0},
+		"C": {Letter: "C", Weight: 200},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	rows, err := db.Query("SELECT lower_case FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Distinct[bool](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bool{false, true}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestDistinctBy(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := DistinctBy(rows, func(t Test) bool {
+		return t.Lowercase
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := records[:2]
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestOne(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests WHERE letter='A'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := One[Test](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records[0], got) {
+		t.Errorf("Expected %v, got %v", records[0], got)
+	}
+}
+
+func TestOneErrors(t *testing.T) {
+	cases := []struct {
+		err   error
+		desc  string
+		query string
+		opts  []Option
+	}{
+		{
+			desc:  "No rows",
+			query: "SELECT * FROM tests WHERE letter='Z'",
+			err:   sql.ErrNoRows,
+		},
+		{
+			desc:  "Too many rows",
+			query: "SELECT * FROM tests",
+			err:   ErrTooManyRows,
+		},
+		{
+			desc:  "Too many rows with limit",
+			query: "SELECT * FROM tests LIMIT 2",
+			err:   ErrTooManyRows,
+			opts:  []Option{Limit(1)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := One[Test](rows, tc.opts...); !errors.Is(err, tc.err) {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestEach(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		got  []Test
+		dest Test
+	)
+	err = Each(&dest, rows, func() error {
+		got = append(got, dest)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records, got) {
+		t.Errorf("Expected %v, got %v", records, got)
+	}
+}
+
+func TestScalar(t *testing.T) {
+	rows, err := db.Query("SELECT count(*) FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Scalar[int](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != len(records) {
+		t.Errorf("Expected %d, got %d", len(records), got)
+	}
+
+	t.Run("No rows", func(t *testing.T) {
+		rows, err := db.Query("SELECT weight FROM tests WHERE letter='Z'")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Scalar[int](rows); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected %v, got %v", sql.ErrNoRows, err)
+		}
+	})
+}
+
+func TestReduce(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Reduce(rows, 0, func(sum int, t Test) int {
+		return sum + t.Weight
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := 300; got != expected {
+		t.Errorf("Expected %d, got %d", expected, got)
+	}
+}
+
+func TestForEachBatch(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]Test
+	err = ForEachBatch(rows, 2, func(batch []Test) error {
+		got = append(got, append([]Test(nil), batch...))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]Test{records[:2], records[2:]}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
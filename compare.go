@@ -0,0 +1,146 @@
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+)
+
+// Queryer executes queries returning rows, it's implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// QueryDiff contains the rows returned by only one of the queries compared.
+type QueryDiff struct {
+	OnlyInA []map[string]interface{}
+	OnlyInB []map[string]interface{}
+}
+
+// Equal returns whether both queries returned the same rows.
+func (d QueryDiff) Equal() bool {
+	return len(d.OnlyInA) == 0 && len(d.OnlyInB) == 0
+}
+
+// CompareQueries executes query on a and b and returns the rows that are present in only one
+// of the results, it's useful to verify the consistency of replicas or that a migration didn't
+// change the data.
+//
+// Rows are compared as a whole regardless of their order, duplicated rows must appear the
+// same number of times on both sides. Values are compared like HashRow encodes them and byte
+// slices are treated as strings.
+func CompareQueries(ctx context.Context, a, b Queryer, query string, args ...interface{}) (QueryDiff, error) {
+	rowsA, columns, err := queryCanonical(ctx, a, nil, query, args)
+	if err != nil {
+		return QueryDiff{}, errorf("querying a: %w", err)
+	}
+	rowsB, _, err := queryCanonical(ctx, b, columns, query, args)
+	if err != nil {
+		return QueryDiff{}, errorf("querying b: %w", err)
+	}
+
+	pending := make(map[string][]map[string]interface{}, len(rowsA))
+	for _, r := range rowsA {
+		pending[r.key] = append(pending[r.key], r.values)
+	}
+
+	var diff QueryDiff
+	for _, r := range rowsB {
+		if matches := pending[r.key]; len(matches) > 0 {
+			pending[r.key] = matches[1:]
+			continue
+		}
+		diff.OnlyInB = append(diff.OnlyInB, r.values)
+	}
+	// Iterate rowsA to keep the order
+	for _, r := range rowsA {
+		if matches := pending[r.key]; len(matches) > 0 {
+			pending[r.key] = matches[1:]
+			diff.OnlyInA = append(diff.OnlyInA, r.values)
+		}
+	}
+
+	return diff, nil
+}
+
+// canonicalRow is a row with its canonical encoding.
+type canonicalRow struct {
+	values map[string]interface{}
+	key    string
+}
+
+// queryCanonical executes the query and returns its rows. If columns is not nil, the result
+// must have the same columns, in any order.
+func queryCanonical(ctx context.Context, q Queryer, columns []string, query string, args []interface{}) ([]canonicalRow, []string, error) {
+	rows, err := queryContext(ctx, q, contextTag(ctx), query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	got, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	if columns == nil {
+		columns = got
+	} else if !sameColumns(columns, got) {
+		return nil, nil, errorf("expected columns %v, got %v", columns, got)
+	}
+
+	values := make([]interface{}, len(got))
+	fields := make([]interface{}, len(got))
+	for i := range values {
+		fields[i] = &values[i]
+	}
+
+	var (
+		result []canonicalRow
+		key    []byte
+	)
+	for rows.Next() {
+		if err := hookNext(); err != nil {
+			return nil, nil, err
+		}
+		if err := hookScanRow(rows.Scan(fields...)); err != nil {
+			return nil, nil, err
+		}
+
+		m := make(map[string]interface{}, len(got))
+		for i, c := range got {
+			v := values[i]
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			m[c] = v
+		}
+
+		key = key[:0]
+		for _, c := range columns {
+			if key, err = appendCanonical(key, reflect.ValueOf(m[c])); err != nil {
+				return nil, nil, errorf("encoding column %q: %w", c, err)
+			}
+		}
+		result = append(result, canonicalRow{values: m, key: string(key)})
+	}
+
+	return result, columns, rows.Err()
+}
+
+// sameColumns returns whether a and b contain the same column names.
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]int, len(a))
+	for _, c := range a {
+		set[c]++
+	}
+	for _, c := range b {
+		if set[c] == 0 {
+			return false
+		}
+		set[c]--
+	}
+	return true
+}
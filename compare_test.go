@@ -0,0 +1,59 @@
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+type queryerFunc func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+
+func (f queryerFunc) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return f(ctx, query, args...)
+}
+
+func TestCompareQueries(t *testing.T) {
+	ctx := context.Background()
+	query := "SELECT letter, weight FROM tests"
+
+	t.Run("Equal", func(t *testing.T) {
+		diff, err := CompareQueries(ctx, db, db, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !diff.Equal() {
+			t.Errorf("Expected no differences, got %v", diff)
+		}
+	})
+
+	t.Run("Different", func(t *testing.T) {
+		b := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			return db.QueryContext(ctx, "SELECT weight, letter FROM tests WHERE letter <> 'b' UNION ALL SELECT 1, 'D'")
+		})
+		expected := QueryDiff{
+			OnlyInA: []map[string]interface{}{{"letter": "b", "weight": int64(0)}},
+			OnlyInB: []map[string]interface{}{{"letter": "D", "weight": int64(1)}},
+		}
+
+		diff, err := CompareQueries(ctx, db, b, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, diff) {
+			t.Errorf("Expected %v, got %v", expected, diff)
+		}
+	})
+
+	t.Run("Different columns", func(t *testing.T) {
+		b := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			return db.QueryContext(ctx, "SELECT letter FROM tests")
+		})
+
+		if _, err := CompareQueries(ctx, db, b, query); err == nil {
+			t.Fatal("Expected an error and got nil")
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package sqan
+
+import "context"
+
+// optionsKey is the key of the options attached to a context.
+type optionsKey struct{}
+
+// WithOptions returns a copy of ctx carrying opts, which are used by the functions taking a context, like
+// Query, Backfill, SelectSharded and SelectHedged, before the options passed to them. It lets middleware
+// set per-request options once, like Strict.
+//
+// The options are added to the ones ctx already carries. Options that record results, like CollectStats
+// and Report, shouldn't be attached as several queries may use them concurrently.
+func WithOptions(ctx context.Context, opts ...Option) context.Context {
+	return context.WithValue(ctx, optionsKey{}, contextOptions(ctx, opts))
+}
+
+// contextTag returns the tag set by the options carried by ctx.
+func contextTag(ctx context.Context) string {
+	var o options
+	for _, opt := range contextOptions(ctx, nil) {
+		opt(&o)
+	}
+	return o.tag
+}
+
+// contextOptions returns the options carried by ctx followed by opts.
+func contextOptions(ctx context.Context, opts []Option) []Option {
+	inherited, _ := ctx.Value(optionsKey{}).([]Option)
+	if len(inherited) == 0 {
+		return opts
+	}
+
+	merged := make([]Option, 0, len(inherited)+len(opts))
+	merged = append(merged, inherited...)
+	return append(merged, opts...)
+}
@@ -0,0 +1,26 @@
+package sqan
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithOptions(t *testing.T) {
+	ctx := WithOptions(context.Background(), AllowUnknownColumns())
+	ctx = WithOptions(ctx, Strict())
+
+	if got := len(contextOptions(ctx, []Option{Limit(1)})); got != 3 {
+		t.Errorf("Expected 3 options, got %d", got)
+	}
+
+	// Strict fails as the other fields aren't populated
+	if _, err := Query[Test](ctx, db, "SELECT letter FROM tests"); ErrorCode(err) != CodeUnsetField {
+		t.Errorf("Expected an unset field error, got %v", err)
+	}
+
+	cursor, err := Query[Test](context.Background(), db, "SELECT letter FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor.Close()
+}
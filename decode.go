@@ -0,0 +1,139 @@
+package sqan
+
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+var (
+	_bytesType = reflect.TypeOf([]byte(nil))
+
+	// anyDecoders convert the values returned by the drivers into the types used for interface{} fields
+	anyDecoders = map[reflect.Type]func(src interface{}) (interface{}, error){
+		reflect.TypeOf(""):         convertTo[string],
+		reflect.TypeOf(int64(0)):   convertTo[int64],
+		reflect.TypeOf(float64(0)): convertTo[float64],
+		reflect.TypeOf(false):      convertTo[bool],
+		_timeType:                  decodeTime,
+		_bytesType:                 convertTo[[]byte],
+	}
+)
+
+// anyValue scans a column into an interface{} field, decoding the value into typ.
+type anyValue struct {
+	field reflect.Value
+	typ   reflect.Type
+}
+
+// Scan implements sql.Scanner.
+func (a anyValue) Scan(src interface{}) error {
+	if src == nil {
+		a.field.Set(reflect.Zero(a.field.Type()))
+		return nil
+	}
+
+	v, err := anyDecoders[a.typ](src)
+	if err != nil {
+		return err
+	}
+	a.field.Set(reflect.ValueOf(v))
+	return nil
+}
+
+// convertTo converts src into a T the same way database/sql does when scanning.
+func convertTo[T any](src interface{}) (interface{}, error) {
+	var n sql.Null[T]
+	if err := n.Scan(src); err != nil {
+		return nil, err
+	}
+	return n.V, nil
+}
+
+// decodeTime keeps the values of time columns as the driver returns them, database/sql can't parse times
+// from text. Drivers returning text, like MySQL without parseTime, may even return values that aren't valid
+// times, like the TIME "838:59:59".
+func decodeTime(src interface{}) (interface{}, error) {
+	if b, ok := src.([]byte); ok {
+		// The driver may reuse the bytes on the next row
+		return bytes.Clone(b), nil
+	}
+	return src, nil
+}
+
+// decodeTypes returns the types the columns scanned into interface{} fields are decoded into, based on
+// the types reported by the driver. It returns nil if there are no such fields or the driver doesn't
+// report the types, in which case the values are stored as the driver returns them.
+func decodeTypes(rows *sql.Rows, columnFields []*field) []reflect.Type {
+	hasAny := false
+	for _, f := range columnFields {
+		if f != nil && isEmptyInterface(f.typ) {
+			hasAny = true
+			break
+		}
+	}
+	if !hasAny {
+		return nil
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil
+	}
+
+	decode := make([]reflect.Type, len(columnFields))
+	for i, f := range columnFields {
+		if f != nil && isEmptyInterface(f.typ) {
+			decode[i] = decodeType(types[i])
+		}
+	}
+	return decode
+}
+
+// decodeType returns the type a column is decoded into, or nil if it's unknown.
+func decodeType(ct *sql.ColumnType) reflect.Type {
+	if st := ct.ScanType(); st != nil {
+		switch st.Kind() {
+		case reflect.String:
+			return reflect.TypeOf("")
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return reflect.TypeOf(int64(0))
+		case reflect.Float32, reflect.Float64:
+			return reflect.TypeOf(float64(0))
+		case reflect.Bool:
+			return reflect.TypeOf(false)
+		}
+		if st == _timeType {
+			return _timeType
+		}
+	}
+
+	// Fall back to the database type for drivers that scan everything into []byte or interface{}. Only
+	// exact names are matched, other types like NUMERIC, INTERVAL, arrays and ranges keep the driver's value
+	name := strings.ToUpper(strings.TrimSpace(ct.DatabaseTypeName()))
+	if i := strings.IndexByte(name, '('); i != -1 {
+		name = strings.TrimSpace(name[:i])
+	}
+	switch name {
+	case "INT", "INT2", "INT4", "INT8", "TINYINT", "SMALLINT", "MEDIUMINT", "INTEGER", "BIGINT":
+		return reflect.TypeOf(int64(0))
+	case "FLOAT", "FLOAT4", "FLOAT8", "REAL", "DOUBLE", "DOUBLE PRECISION":
+		return reflect.TypeOf(float64(0))
+	case "BOOL", "BOOLEAN":
+		return reflect.TypeOf(false)
+	case "DATE", "TIME", "TIMETZ", "TIMESTAMP", "TIMESTAMPTZ", "DATETIME":
+		return _timeType
+	case "CHAR", "BPCHAR", "VARCHAR", "CHARACTER", "CHARACTER VARYING", "NCHAR", "NVARCHAR", "TEXT", "NAME", "UUID":
+		return reflect.TypeOf("")
+	case "BYTEA", "BLOB", "BINARY", "VARBINARY":
+		return _bytesType
+	}
+	return nil
+}
+
+// isEmptyInterface returns whether t is interface{}.
+func isEmptyInterface(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface && t.NumMethod() == 0
+}
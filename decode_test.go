@@ -0,0 +1,143 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GGP1/sqan/sqantest"
+)
+
+func TestDecodeInterface(t *testing.T) {
+	type dynamic struct {
+		Letter    interface{}
+		Weight    interface{}
+		Lowercase interface{} `db:"lower_case"`
+		Null      interface{}
+	}
+	rows, err := db.Query("SELECT letter, weight, lower_case, NULL::text AS null FROM tests WHERE letter='A'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got dynamic
+	if err := Row(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := dynamic{Letter: "A", Weight: int64(100), Lowercase: false}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestDecodeType(t *testing.T) {
+	rows, err := db.Query("SELECT 1::int2, 1.5::float8, 'a'::varchar, true, now(), '\\x01'::bytea")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []reflect.Type{
+		reflect.TypeOf(int64(0)),
+		reflect.TypeOf(float64(0)),
+		reflect.TypeOf(""),
+		reflect.TypeOf(false),
+		reflect.TypeOf(time.Time{}),
+		reflect.TypeOf([]byte(nil)),
+	}
+	for i, ct := range types {
+		if got := decodeType(ct); got != expected[i] {
+			t.Errorf("%s: expected %v, got %v", ct.DatabaseTypeName(), expected[i], got)
+		}
+	}
+}
+
+func TestConvertTo(t *testing.T) {
+	cases := []struct {
+		src      interface{}
+		convert  func(interface{}) (interface{}, error)
+		expected interface{}
+	}{
+		{src: []byte("12"), convert: convertTo[int64], expected: int64(12)},
+		{src: []byte("1.5"), convert: convertTo[float64], expected: 1.5},
+		{src: []byte("abc"), convert: convertTo[string], expected: "abc"},
+		{src: int64(1), convert: convertTo[bool], expected: true},
+		{src: "abc", convert: convertTo[[]byte], expected: []byte("abc")},
+	}
+
+	for _, tc := range cases {
+		got, err := tc.convert(tc.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tc.expected, got) {
+			t.Errorf("Expected %#v, got %#v", tc.expected, got)
+		}
+	}
+
+	if _, err := convertTo[int64]([]byte("a")); err == nil {
+		t.Error("Expected an error converting a non-numeric value")
+	}
+}
+
+func TestDecodeDriverValues(t *testing.T) {
+	type dynamic struct {
+		Interval interface{}
+		Array    interface{}
+		Range    interface{}
+		Numeric  interface{}
+	}
+	rows, err := db.Query(`SELECT '1 day'::interval AS interval, ARRAY[1, 2]::int4[] AS array,
+		daterange('2021-01-01', '2021-02-01') AS range, 1.50::numeric AS numeric`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got dynamic
+	if err := Row(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := dynamic{
+		Interval: []byte("1 day"),
+		Array:    []byte("{1,2}"),
+		Range:    []byte("[2021-01-01,2021-02-01)"),
+		Numeric:  []byte("1.50"),
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestDecodeTimeText(t *testing.T) {
+	type dynamic struct {
+		Date     interface{}
+		Duration interface{}
+	}
+	snapshot := sqantest.Snapshot{
+		Columns: []sqantest.Column{{Name: "date", DatabaseType: "DATE"}, {Name: "duration", DatabaseType: "TIME"}},
+		Rows: [][]sqantest.Value{
+			{{V: []byte("2021-01-01")}, {V: []byte("838:59:59")}},
+		},
+	}
+	rows, err := snapshot.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got dynamic
+	if err := Row(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := dynamic{Date: []byte("2021-01-01"), Duration: []byte("838:59:59")}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %#v, got %#v", expected, got)
+	}
+}
@@ -0,0 +1,52 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// ColumnInfo describes a column of a result.
+type ColumnInfo struct {
+	// GoType is the type interface{} fields receive for the column, it's the driver's scan type
+	// if the column type isn't one of the known ones
+	GoType reflect.Type
+	Name   string
+	// DatabaseType is the name of the database type, empty if the driver doesn't report it
+	DatabaseType string
+	// Length is the length of variable-length types like VARCHAR(n), 0 if unknown or not applicable
+	Length int64
+	// Nullable is whether the column may contain NULL, it's false if the driver doesn't report it
+	Nullable bool
+}
+
+// DescribeRows returns the columns of the result in order with the metadata reported by the driver.
+// It's useful to build dynamic user interfaces and to generate structs from a query.
+//
+// It doesn't read or close rows, which can be scanned afterwards.
+func DescribeRows(rows *sql.Rows) ([]ColumnInfo, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]ColumnInfo, len(types))
+	for i, ct := range types {
+		c := ColumnInfo{
+			Name:         ct.Name(),
+			DatabaseType: ct.DatabaseTypeName(),
+			GoType:       decodeType(ct),
+		}
+		if c.GoType == nil {
+			c.GoType = ct.ScanType()
+		}
+		if length, ok := ct.Length(); ok {
+			c.Length = length
+		}
+		if nullable, ok := ct.Nullable(); ok {
+			c.Nullable = nullable
+		}
+		columns[i] = c
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,43 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribeRows(t *testing.T) {
+	rows, err := db.Query("SELECT letter, weight, lower_case, 'abc'::varchar(5) AS code FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	got, err := DescribeRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ColumnInfo{
+		{Name: "letter", DatabaseType: "TEXT", GoType: reflect.TypeOf("")},
+		{Name: "weight", DatabaseType: "INT4", GoType: reflect.TypeOf(int64(0))},
+		{Name: "lower_case", DatabaseType: "BOOL", GoType: reflect.TypeOf(false)},
+		{Name: "code", DatabaseType: "VARCHAR", GoType: reflect.TypeOf("")},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d columns, got %d", len(expected), len(got))
+	}
+	for i, c := range got {
+		e := expected[i]
+		if c.Name != e.Name || c.DatabaseType != e.DatabaseType || c.GoType != e.GoType {
+			t.Errorf("Expected %v, got %v", e, c)
+		}
+	}
+	if got[3].Length != 5 {
+		t.Errorf("Expected varchar length 5, got %d", got[3].Length)
+	}
+
+	// The rows can still be scanned
+	if !rows.Next() {
+		t.Error("Expected the rows to be readable after describing them")
+	}
+}
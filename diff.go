@@ -0,0 +1,98 @@
+package sqan
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+)
+
+// FieldChange is a column whose value differs between two rows.
+type FieldChange struct {
+	Column string
+	// Field is the path to the struct field, like "Address.Street"
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// Diff returns the columns whose values differ between before and after, which must be structs
+// of the same type or pointers to them. The changes are sorted in the order the fields are declared.
+//
+// Values are compared the same way HashRow encodes them, for example two times representing
+// the same instant in different locations are equal.
+func Diff(before, after interface{}) ([]FieldChange, error) {
+	beforeValue, err := structValue(before)
+	if err != nil {
+		return nil, err
+	}
+	afterValue, err := structValue(after)
+	if err != nil {
+		return nil, err
+	}
+	if beforeValue.Type() != afterValue.Type() {
+		return nil, errorf("can't compare %s with %s", beforeValue.Type(), afterValue.Type())
+	}
+
+	mapping, err := typeMapping(beforeValue.Type(), naming{})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		changes []FieldChange
+		indices [][]int
+		a, b    []byte
+	)
+	for c, f := range mapping {
+		if isComposite(f.typ) {
+			// Compared by its fields
+			continue
+		}
+
+		beforeField, _ := fieldByIndex(beforeValue, f.index)
+		afterField, _ := fieldByIndex(afterValue, f.index)
+		if a, err = appendCanonical(a[:0], beforeField); err != nil {
+			return nil, errorf("comparing column %q: %w", c, err)
+		}
+		if b, err = appendCanonical(b[:0], afterField); err != nil {
+			return nil, errorf("comparing column %q: %w", c, err)
+		}
+		if bytes.Equal(a, b) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{
+			Column: c,
+			Field:  f.path,
+			Old:    interfaceOf(beforeField),
+			New:    interfaceOf(afterField),
+		})
+		indices = append(indices, f.index)
+	}
+
+	sort.Sort(byIndex{changes: changes, indices: indices})
+	return changes, nil
+}
+
+// byIndex sorts changes by the index of their fields.
+type byIndex struct {
+	changes []FieldChange
+	indices [][]int
+}
+
+func (b byIndex) Len() int { return len(b.changes) }
+
+func (b byIndex) Less(i, j int) bool { return lessIndex(b.indices[i], b.indices[j]) }
+
+func (b byIndex) Swap(i, j int) {
+	b.changes[i], b.changes[j] = b.changes[j], b.changes[i]
+	b.indices[i], b.indices[j] = b.indices[j], b.indices[i]
+}
+
+// interfaceOf returns the value held by v or nil if it's the zero Value.
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,59 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := records[0]
+	after := before
+	after.Weight = 150
+	after.Sub.Exported = false
+
+	expected := []FieldChange{
+		{Column: "exported", Field: "Sub.Exported", Old: before.Sub.Exported, New: after.Sub.Exported},
+		{Column: "weight", Field: "Weight", Old: before.Weight, New: after.Weight},
+	}
+
+	got, err := Diff(before, &after)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	cases := []struct {
+		before interface{}
+		after  interface{}
+		desc   string
+	}{
+		{
+			desc:   "Nil",
+			before: nil,
+			after:  Test{},
+		},
+		{
+			desc:   "Not a struct",
+			before: 1,
+			after:  2,
+		},
+		{
+			desc:   "Different types",
+			before: Test{},
+			after:  Sub{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, err := Diff(tc.before, tc.after); err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+		})
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/GGP1/sqan
 
-go 1.17
+go 1.23
 
 require github.com/lib/pq v1.10.3
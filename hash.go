@@ -0,0 +1,127 @@
+package sqan
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	_valuerInterface = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	_timeType        = reflect.TypeOf(time.Time{})
+)
+
+// HashRow returns a hash of the mapped fields of v, which must be a struct or a pointer to one.
+//
+// Fields are hashed in the order of their column names and their values are encoded
+// canonically: integers, floats, strings and times (in UTC) hash the same regardless of
+// their Go type, and types implementing driver.Valuer are hashed by the value they return.
+// This way rows read from different tables or databases can be compared by their hashes.
+func HashRow(v interface{}) (uint64, error) {
+	value, err := structValue(v)
+	if err != nil {
+		return 0, err
+	}
+
+	mapping, err := typeMapping(value.Type(), naming{})
+	if err != nil {
+		return 0, err
+	}
+
+	columns := make([]string, 0, len(mapping))
+	for c := range mapping {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+
+	h := fnv.New64a()
+	buf := make([]byte, 0, 64)
+	for _, c := range columns {
+		f := mapping[c]
+		if isComposite(f.typ) {
+			// Represented by its fields
+			continue
+		}
+
+		fieldValue, _ := fieldByIndex(value, f.index)
+		b, err := appendCanonical(appendBytes(buf[:0], []byte(c)), fieldValue)
+		if err != nil {
+			return 0, errorf("hashing column %q: %w", c, err)
+		}
+		h.Write(b)
+		buf = b
+	}
+
+	return h.Sum64(), nil
+}
+
+// appendCanonical appends the canonical encoding of v to b.
+//
+// Each value is prefixed by a byte identifying its type and variable length values are
+// prefixed by their length, so the encoding of two different values never matches.
+func appendCanonical(b []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return append(b, 'N'), nil
+	}
+
+	if v.Type().Implements(_valuerInterface) {
+		value, err := v.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return nil, err
+		}
+		return appendCanonical(b, reflect.ValueOf(value))
+	}
+	if v.Kind() == reflect.Ptr {
+		return appendCanonical(b, v.Elem())
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(_valuerInterface) {
+		return appendCanonical(b, v.Addr())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.AppendBool(append(b, 'b'), v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(append(b, 'i'), v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(append(b, 'i'), v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.AppendFloat(append(b, 'f'), v.Float(), 'g', -1, 64), nil
+	case reflect.String:
+		return appendBytes(append(b, 's'), []byte(v.String())), nil
+	case reflect.Interface:
+		return appendCanonical(b, v.Elem())
+	case reflect.Struct:
+		if v.Type() == _timeType {
+			t := v.Interface().(time.Time).UTC().Format(time.RFC3339Nano)
+			return appendBytes(append(b, 't'), []byte(t)), nil
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendBytes(append(b, 'x'), v.Bytes()), nil
+		}
+	}
+
+	return appendBytes(append(b, 'v'), []byte(fmt.Sprint(v.Interface()))), nil
+}
+
+// isComposite returns whether t is a struct represented by its mapped fields rather than a
+// value on its own, like time.Time or the types implementing sql.Scanner or driver.Valuer.
+func isComposite(t reflect.Type) bool {
+	t = baseType(t)
+	if t.Kind() != reflect.Struct || t == _timeType {
+		return false
+	}
+	ptr := reflect.PtrTo(t)
+	return !ptr.Implements(_scannerInterface) && !ptr.Implements(_valuerInterface)
+}
+
+func appendBytes(b, value []byte) []byte {
+	b = strconv.AppendInt(b, int64(len(value)), 10)
+	b = append(b, ':')
+	return append(b, value...)
+}
@@ -0,0 +1,62 @@
+package sqan
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestHashRow(t *testing.T) {
+	type row struct {
+		ID        int
+		Name      string
+		CreatedAt time.Time `db:"created_at"`
+	}
+	type otherRow struct {
+		CreatedAt time.Time      `db:"created_at"`
+		Name      sql.NullString `db:"name"`
+		ID        int64          `db:"id"`
+	}
+
+	now := time.Now()
+	r := row{ID: 1, Name: "Alice", CreatedAt: now}
+	hash, err := HashRow(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Equal", func(t *testing.T) {
+		other := &otherRow{
+			ID:        1,
+			Name:      sql.NullString{String: "Alice", Valid: true},
+			CreatedAt: now.In(time.FixedZone("UTC+1", 3600)),
+		}
+		got, err := HashRow(other)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hash != got {
+			t.Errorf("Expected %d, got %d", hash, got)
+		}
+	})
+
+	t.Run("Different", func(t *testing.T) {
+		r := r
+		r.Name = "Bob"
+		got, err := HashRow(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hash == got {
+			t.Errorf("Expected hashes to differ, both are %d", got)
+		}
+	})
+
+	t.Run("Not a struct", func(t *testing.T) {
+		if _, err := HashRow(1); err == nil {
+			t.Fatal("Expected an error and got nil")
+		}
+	})
+}
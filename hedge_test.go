@@ -0,0 +1,74 @@
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSelectHedged(t *testing.T) {
+	ctx := context.Background()
+	query := "SELECT * FROM tests"
+
+	t.Run("Slow", func(t *testing.T) {
+		canceled := make(chan struct{})
+		slow := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		})
+
+		var got []Test
+		if err := SelectHedged(ctx, []Queryer{slow, db}, 10*time.Millisecond, &got, query); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(records, got) {
+			t.Errorf("Expected %v, got %v", records, got)
+		}
+
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Error("Expected the slow query to be canceled")
+		}
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		failing := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			return nil, errors.New("connection refused")
+		})
+
+		var got []Test
+		// The delay is never reached, the failure starts the next replica
+		if err := SelectHedged(ctx, []Queryer{failing, db}, time.Hour, &got, query); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(records, got) {
+			t.Errorf("Expected %v, got %v", records, got)
+		}
+	})
+
+	t.Run("All fail", func(t *testing.T) {
+		errA, errB := errors.New("a"), errors.New("b")
+		a := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			return nil, errA
+		})
+		b := queryerFunc(func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+			return nil, errB
+		})
+
+		var got []Test
+		err := SelectHedged(ctx, []Queryer{a, b}, time.Millisecond, &got, query)
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("Expected the errors of both replicas, got %v", err)
+		}
+		if got != nil {
+			t.Errorf("Expected dest to be unmodified, got %v", got)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package sqan
+
+import "sync/atomic"
+
+// hooks are functions called at points of the scanning process. They can only be set with SetTestHooks,
+// which exists in builds with the sqantest tag, so testHooks is always nil otherwise.
+type hooks struct {
+	afterNext    func() error
+	afterScanRow func(err error) error
+}
+
+var testHooks atomic.Pointer[hooks]
+
+// hookNext calls the afterNext hook, if set, after moving to a row.
+func hookNext() error {
+	if h := testHooks.Load(); h != nil && h.afterNext != nil {
+		return h.afterNext()
+	}
+	return nil
+}
+
+// hookScanRow calls the afterScanRow hook, if set, with the error of scanning a row and returns the error
+// it returns instead.
+func hookScanRow(err error) error {
+	if h := testHooks.Load(); h != nil && h.afterScanRow != nil {
+		return h.afterScanRow(err)
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+//go:build sqantest
+
+package sqan
+
+// TestHooks are functions called by the package while scanning, to write deterministic tests for the
+// cancellation, partial results and error paths of data layers. They are only available in builds with
+// the sqantest tag, like go test -tags sqantest ./...
+type TestHooks struct {
+	// AfterNext is called after moving to a row, before scanning it. If it returns an error the iteration
+	// stops and the error is returned as if it came from the driver, with the rows scanned until then,
+	// like when the context of a query is canceled.
+	AfterNext func() error
+	// AfterScanRow is called with the error of scanning a row into a destination, nil if it succeeded.
+	// The error it returns replaces it.
+	AfterScanRow func(err error) error
+}
+
+// SetTestHooks sets the hooks called by all the functions of the package and returns a function that
+// removes them, to be deferred or passed to t.Cleanup. As they are global, tests using them can't run
+// in parallel.
+func SetTestHooks(h TestHooks) (reset func()) {
+	testHooks.Store(&hooks{afterNext: h.AfterNext, afterScanRow: h.AfterScanRow})
+	return func() {
+		testHooks.Store(nil)
+	}
+}
@@ -0,0 +1,134 @@
+//go:build sqantest
+
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTestHooks(t *testing.T) {
+	t.Run("AfterNext", func(t *testing.T) {
+		var n int
+		reset := SetTestHooks(TestHooks{
+			AfterNext: func() error {
+				if n++; n == 2 {
+					return context.Canceled
+				}
+				return nil
+			},
+		})
+		defer reset()
+
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []Test
+		err = Rows(&got, rows)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if !reflect.DeepEqual(records[:1], got) {
+			t.Errorf("Expected the rows scanned before the error %v, got %v", records[:1], got)
+		}
+	})
+
+	t.Run("AfterScanRow", func(t *testing.T) {
+		errScan := errors.New("scan failed")
+		reset := SetTestHooks(TestHooks{
+			AfterScanRow: func(err error) error {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return errScan
+			},
+		})
+		defer reset()
+
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := All[Test](rows); !errors.Is(err, errScan) {
+			t.Errorf("Expected %v, got %v", errScan, err)
+		}
+	})
+
+	t.Run("ScanPaths", func(t *testing.T) {
+		errScan := errors.New("scan failed")
+		reset := SetTestHooks(TestHooks{
+			AfterScanRow: func(err error) error { return errScan },
+		})
+		defer reset()
+
+		cases := []struct {
+			desc  string
+			query string
+			scan  func(rows *sql.Rows) error
+		}{
+			{
+				desc:  "Scalar",
+				query: "SELECT count(*) FROM tests",
+				scan: func(rows *sql.Rows) error {
+					_, err := Scalar[int](rows)
+					return err
+				},
+			},
+			{
+				desc:  "KeyValues",
+				query: "SELECT 'letter' AS key, 'A' AS value",
+				scan: func(rows *sql.Rows) error {
+					var got Test
+					return KeyValues(&got, rows)
+				},
+			},
+			{
+				desc:  "Pivot",
+				query: "SELECT letter, weight, lower_case FROM tests",
+				scan: func(rows *sql.Rows) error {
+					_, err := Pivot[bool, string, int](rows, "lower_case", "letter", "weight")
+					return err
+				},
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.desc, func(t *testing.T) {
+				rows, err := db.Query(tc.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if err := tc.scan(rows); !errors.Is(err, errScan) {
+					t.Errorf("Expected %v, got %v", errScan, err)
+				}
+			})
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		reset := SetTestHooks(TestHooks{
+			AfterNext: func() error { return context.Canceled },
+		})
+		reset()
+
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := All[Test](rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(records, got) {
+			t.Errorf("Expected %v, got %v", records, got)
+		}
+	})
+}
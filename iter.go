@@ -0,0 +1,265 @@
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+	"reflect"
+)
+
+// Cursor scans the rows of a result one at a time into values of type T.
+type Cursor[T any] struct {
+	st  *stream[T]
+	err error
+}
+
+// Query executes query and returns a cursor over its rows. The columns are matched with the
+// fields of T only once, when the cursor is created.
+//
+// The cursor must be closed if it's not consumed until Next returns false.
+func Query[T any](ctx context.Context, q Queryer, query string, args ...interface{}) (*Cursor[T], error) {
+	o := newOptions(contextOptions(ctx, nil))
+	rows, err := queryContext(ctx, q, o.tag, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := newStream[T](rows, o)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+
+	return &Cursor[T]{st: st}, nil
+}
+
+// Next scans the following row and returns it. It returns false when there are no more rows or an error
+// occurred, which is returned by Err, and closes the cursor.
+func (c *Cursor[T]) Next() (T, bool) {
+	if c.err != nil {
+		var zero T
+		return zero, false
+	}
+
+	v, ok, err := c.st.next()
+	if err != nil {
+		c.err = err
+	}
+	if !ok {
+		c.st.rows.Close()
+	}
+	return v, ok
+}
+
+// Err returns the error, if any, that occurred during the iteration.
+func (c *Cursor[T]) Err() error {
+	return c.err
+}
+
+// Close closes the cursor, preventing further scanning. It's safe to call it more than once.
+func (c *Cursor[T]) Close() error {
+	return c.st.rows.Close()
+}
+
+// Iter returns a sequence of the rows scanned into values of type T, to consume the result in a for
+// range loop without keeping it in memory.
+//
+// Rows are closed when the sequence ends or the loop is stopped. Errors, including the one returned by
+// rows.Err(), are yielded with the zero value of T and end the sequence.
+func Iter[T any](rows *sql.Rows, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+		o := newOptions(opts)
+		defer o.done()
+
+		var zero T
+		st, err := newStream[T](rows, o)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		// The rows may have been replaced by Resume
+		defer func() { st.rows.Close() }()
+
+		for {
+			v, ok, err := st.next()
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !ok || !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// MergeSorted scans two results sorted by the same criteria, like the ones returned by different shards,
+// and yields their rows as a single sorted sequence. less reports whether x must be yielded before y,
+// rows of a are yielded first when they are equal.
+//
+// Both results are closed when the sequence ends or the loop is stopped. Errors are yielded with the
+// zero value of T and end the sequence. The options apply to each result separately, except Resume,
+// which is ignored.
+func MergeSorted[T any](a, b *sql.Rows, less func(x, y T) bool, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer a.Close()
+		defer b.Close()
+		// Each result has its own options so that per-call state, like the rows counted by Limit, isn't shared
+		oa, ob := newOptions(opts), newOptions(opts)
+		defer oa.done()
+		// A single function can't resume two results
+		oa.resume, ob.resume = nil, nil
+
+		var zero T
+		sa, err := newStream[T](a, oa)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		sb, err := newStream[T](b, ob)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		x, okA, err := sa.next()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		y, okB, err := sb.next()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for okA || okB {
+			if okA && (!okB || !less(y, x)) {
+				if !yield(x, nil) {
+					return
+				}
+				x, okA, err = sa.next()
+			} else {
+				if !yield(y, nil) {
+					return
+				}
+				y, okB, err = sb.next()
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
+
+// Resume makes Query and Iter continue the iteration when the rows fail, like when the connection is
+// lost during a long export. fn is called with the value of the column key in the last row returned,
+// or nil if none was, and returns the rows following it, usually by executing the query again with
+// "WHERE key > $1". The rows must be sorted by key. If fn returns an error, it's returned along with
+// the one that ended the rows.
+//
+//	sqan.Resume("id", func(lastKey interface{}) (*sql.Rows, error) {
+//		return db.QueryContext(ctx, "SELECT * FROM events WHERE id > $1 ORDER BY id", lastKey)
+//	})
+//
+// Only the errors returned by the driver while iterating are resumed, not the ones scanning the rows.
+// If T isn't a struct, the key is the value of the rows itself.
+func Resume(key string, fn func(lastKey interface{}) (*sql.Rows, error)) Option {
+	return func(o *options) {
+		o.resumeKey = key
+		o.resume = fn
+	}
+}
+
+// stream scans the rows of a result one at a time into values of type T.
+type stream[T any] struct {
+	rows *sql.Rows
+	s    *rowScanner
+	o    *options
+	// key is the field holding the key passed to the Resume function, nil if the whole value is the key
+	key *field
+	// last is the key of the last row returned
+	last interface{}
+}
+
+func newStream[T any](rows *sql.Rows, o *options) (*stream[T], error) {
+	st := &stream[T]{rows: rows, o: o}
+	if err := st.init(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// init creates the scanner of the rows and finds the field of the resume key.
+func (st *stream[T]) init() error {
+	s, err := newRowScanner("type parameter", typeOf[T](), st.rows, st.o)
+	if err != nil {
+		return err
+	}
+	st.s = s
+	if s == nil || st.o.resume == nil || s.scannable || s.json != nil {
+		return nil
+	}
+
+	for i, c := range s.columns {
+		if st.o.normalize != nil {
+			c = st.o.normalize(c)
+		}
+		if c == st.o.resumeKey && s.columnFields[i] != nil {
+			st.key = s.columnFields[i]
+			return nil
+		}
+	}
+	return codeErrorf(CodeUnmappedColumn, "couldn't find a field for resume key column %q", st.o.resumeKey)
+}
+
+// next scans the following row, it returns false when there are no more rows or an error occurs.
+func (st *stream[T]) next() (T, bool, error) {
+	var v T
+	// A nil scanner means the result has no columns
+	for st.s == nil || !st.o.next(st.rows) {
+		err := st.o.err(st.rows)
+		if err == nil || st.s == nil || st.o.resume == nil || errors.Is(err, ErrTooManyRows) {
+			return v, false, err
+		}
+		if err := st.resume(err); err != nil {
+			return v, false, err
+		}
+	}
+
+	rv, err := st.s.scan(st.rows)
+	if err != nil {
+		return v, false, err
+	}
+	if st.o.resume != nil {
+		st.recordKey(rv)
+	}
+	return rv.Interface().(T), true, nil
+}
+
+// resume replaces the rows that failed with err with the ones returned by the Resume function.
+func (st *stream[T]) resume(err error) error {
+	rows, resumeErr := st.o.resume(st.last)
+	if resumeErr != nil {
+		return errorf("couldn't resume the rows after %w: %w", err, resumeErr)
+	}
+
+	st.rows.Close()
+	st.rows = rows
+	st.o.hookErr = nil
+	return st.init()
+}
+
+// recordKey saves the key of the row v.
+func (st *stream[T]) recordKey(v reflect.Value) {
+	if st.key == nil {
+		st.last = v.Interface()
+		return
+	}
+	if key, ok := fieldByIndex(reflect.Indirect(v), st.key.index); ok {
+		st.last = key.Interface()
+	}
+}
@@ -0,0 +1,79 @@
+//go:build sqantest
+
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResume(t *testing.T) {
+	// Lose the connection before the second row
+	failed := false
+	calls := 0
+	reset := SetTestHooks(TestHooks{
+		AfterNext: func() error {
+			if calls++; calls == 2 && !failed {
+				failed = true
+				return errors.New("connection lost")
+			}
+			return nil
+		},
+	})
+	defer reset()
+
+	var lastKeys []interface{}
+	resume := Resume("weight", func(lastKey interface{}) (*sql.Rows, error) {
+		lastKeys = append(lastKeys, lastKey)
+		return db.Query("SELECT * FROM tests WHERE weight > $1 ORDER BY weight", lastKey)
+	})
+
+	rows, err := db.Query("SELECT * FROM tests ORDER BY weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	for v, err := range Iter[Test](rows, resume) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+
+	expected := []Test{records[1], records[0], records[2]}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual([]interface{}{0}, lastKeys) {
+		t.Errorf("Expected to resume after key 0, got %v", lastKeys)
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		reset := SetTestHooks(TestHooks{
+			AfterNext: func() error { return context.Canceled },
+		})
+		defer reset()
+
+		errResume := errors.New("resume failed")
+		resume := Resume("weight", func(lastKey interface{}) (*sql.Rows, error) {
+			return nil, errResume
+		})
+		ctx := WithOptions(context.Background(), resume)
+		c, err := Query[Test](ctx, db, "SELECT * FROM tests ORDER BY weight")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		if _, ok := c.Next(); ok {
+			t.Fatal("Expected no rows")
+		}
+		if err := c.Err(); !errors.Is(err, context.Canceled) || !errors.Is(err, errResume) {
+			t.Errorf("Expected both errors, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package sqan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	cursor, err := Query[Test](context.Background(), db, "SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cursor.Close()
+
+	var got []Test
+	for {
+		v, ok := cursor.Next()
+		if !ok {
+			break
+		}
+		got = append(got, v)
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records, got) {
+		t.Errorf("Expected %v, got %v", records, got)
+	}
+}
+
+func TestIter(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	for v, err := range Iter[Test](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	if !reflect.DeepEqual(records[:2], got) {
+		t.Errorf("Expected %v, got %v", records[:2], got)
+	}
+	if rows.Next() {
+		t.Error("Expected rows to be closed after breaking the loop")
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	a, err := db.Query("SELECT * FROM tests WHERE lower_case ORDER BY weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := db.Query("SELECT * FROM tests WHERE NOT lower_case ORDER BY weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	for v, err := range MergeSorted(a, b, func(x, y Test) bool { return x.Weight < y.Weight }) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+
+	expected := []Test{records[1], records[0], records[2]}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	t.Run("Limit", func(t *testing.T) {
+		a, err := db.Query("SELECT * FROM tests WHERE lower_case ORDER BY weight")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := db.Query("SELECT * FROM tests WHERE NOT lower_case ORDER BY weight")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The limit applies to each result
+		var got []Test
+		for v, err := range MergeSorted(a, b, func(x, y Test) bool { return x.Weight < y.Weight }, Limit(1)) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, v)
+		}
+
+		expected := []Test{records[1], records[0]}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package sqan
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var _rawMessageMapType = reflect.TypeOf(map[string]json.RawMessage(nil))
+
+// DecodeNDJSON decodes the newline-delimited JSON objects read from r into dest, a pointer to a
+// slice of structs.
+//
+// The keys of the objects are matched with the fields the same way columns are, so data dumps
+// and fixtures use the same names as the database. Values are decoded using encoding/json and
+// keys that don't match any field return an error.
+func DecodeNDJSON(r io.Reader, dest interface{}) error {
+	value, err := destValue(dest)
+	if err != nil {
+		return err
+	}
+	if value.Kind() != reflect.Slice {
+		return codeErrorf(CodeInvalidDest, "dest must be a pointer to a slice, got %s", reflect.PtrTo(value.Type()))
+	}
+
+	elem := value.Type().Elem()
+	if err := checkPointerLevels("slice element", elem); err != nil {
+		return err
+	}
+	baseElem := baseType(elem)
+	if baseElem.Kind() != reflect.Struct {
+		return codeErrorf(CodeInvalidDest, "slice element must be a struct")
+	}
+
+	mapping, err := typeMapping(baseElem, naming{})
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var object map[string]json.RawMessage
+		if err := dec.Decode(&object); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errorf("object %d: %w", line, err)
+		}
+
+		vPtr := reflect.New(baseElem)
+		v := vPtr.Elem()
+		for key, raw := range object {
+			f, ok := mapping[key]
+			if !ok {
+				return codeErrorf(CodeUnmappedColumn, "object %d: couldn't find a field for key %q", line, key)
+			}
+
+			allocNilPointers(v, f.index)
+			if err := json.Unmarshal(raw, v.FieldByIndex(f.index).Addr().Interface()); err != nil {
+				return errorf("object %d: key %q: %w", line, key, err)
+			}
+		}
+
+		if elem.Kind() == reflect.Ptr {
+			value.Set(reflect.Append(value, vPtr))
+		} else {
+			value.Set(reflect.Append(value, v))
+		}
+	}
+}
+
+// jsonRow encodes the values of the rows as JSON.
+type jsonRow struct {
+	columns []string
+	// documents tells whether a column contains JSON documents, which are passed through
+	documents []bool
+	// binary tells whether a column contains binary data, which is encoded in base64
+	binary []bool
+	values []interface{}
+	fields []interface{}
+}
+
+func newJSONRow(rows *sql.Rows, columns []string) (*jsonRow, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &jsonRow{
+		columns:   columns,
+		documents: make([]bool, len(columns)),
+		binary:    make([]bool, len(columns)),
+		values:    make([]interface{}, len(columns)),
+		fields:    make([]interface{}, len(columns)),
+	}
+	for i, t := range types {
+		switch strings.ToUpper(t.DatabaseTypeName()) {
+		case "JSON", "JSONB":
+			r.documents[i] = true
+		}
+		r.binary[i] = decodeType(t) == _bytesType
+		r.fields[i] = &r.values[i]
+	}
+
+	return r, nil
+}
+
+// scan scans the current row into a map of column names and their values encoded as JSON.
+func (r *jsonRow) scan(rows *sql.Rows) (map[string]json.RawMessage, error) {
+	if err := rows.Scan(r.fields...); err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]json.RawMessage, len(r.columns))
+	for i, v := range r.values {
+		b, err := r.encode(v, r.documents[i], r.binary[i])
+		if err != nil {
+			return nil, errorf("encoding column %q: %w", r.columns[i], err)
+		}
+		m[r.columns[i]] = b
+	}
+
+	return m, nil
+}
+
+// encode returns the JSON encoding of a value returned by the driver.
+//
+// Strings and byte slices are encoded as strings unless they contain a JSON document. Byte slices of
+// binary columns, or that aren't valid UTF-8, are encoded in base64 like encoding/json does.
+func (r *jsonRow) encode(v interface{}, document, binary bool) (json.RawMessage, error) {
+	switch v := v.(type) {
+	case nil:
+		return json.RawMessage("null"), nil
+	case bool:
+		return strconv.AppendBool(nil, v), nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errorf("unsupported value %v", v)
+		}
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	case []byte:
+		if document && json.Valid(v) {
+			return json.RawMessage(v), nil
+		}
+		if binary || !utf8.Valid(v) {
+			return json.Marshal(v)
+		}
+		return json.Marshal(string(v))
+	case string:
+		if document && json.Valid([]byte(v)) {
+			return json.RawMessage(v), nil
+		}
+		return json.Marshal(v)
+	case time.Time:
+		return v.MarshalJSON()
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,116 @@
+package sqan
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRowsJSON(t *testing.T) {
+	expected := []map[string]json.RawMessage{
+		{"letter": json.RawMessage(`"A"`), "weight": json.RawMessage("100"), "doc": json.RawMessage(`{"exported": true}`)},
+		{"letter": json.RawMessage(`"b"`), "weight": json.RawMessage("0"), "doc": json.RawMessage(`{"exported": false}`)},
+		{"letter": json.RawMessage(`"C"`), "weight": json.RawMessage("200"), "doc": json.RawMessage(`{"exported": true}`)},
+	}
+	rows, err := db.Query("SELECT letter, weight, jsonb_build_object('exported', exported) AS doc FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]json.RawMessage
+	if err := Rows(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestRowJSON(t *testing.T) {
+	expected := map[string]json.RawMessage{"letter": json.RawMessage(`"A"`), "lower_case": json.RawMessage("false")}
+	rows, err := db.Query("SELECT letter, lower_case FROM tests WHERE letter=$1", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]json.RawMessage
+	if err := Row(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestRowJSONBinary(t *testing.T) {
+	expected := map[string]json.RawMessage{"data": json.RawMessage(`"/wA="`), "text": json.RawMessage(`"abc"`)}
+	rows, err := db.Query(`SELECT '\xff00'::bytea AS data, 'abc'::text AS text`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]json.RawMessage
+	if err := Row(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestDecodeNDJSON(t *testing.T) {
+	input := `{"letter": "A", "lower_case": false, "weight": 100, "exported": true}
+{"letter": "b", "lower_case": true, "weight": 0, "exported": false}
+{"letter": "C", "lower_case": false, "weight": 200, "exported": true}
+`
+
+	var got []Test
+	if err := DecodeNDJSON(strings.NewReader(input), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records, got) {
+		t.Errorf("Expected %v, got %v", records, got)
+	}
+}
+
+func TestDecodeNDJSONErrors(t *testing.T) {
+	cases := []struct {
+		dest  interface{}
+		desc  string
+		input string
+	}{
+		{
+			desc:  "Not a slice",
+			dest:  &Test{},
+			input: `{"letter": "A"}`,
+		},
+		{
+			desc:  "Unknown key",
+			dest:  &[]Test{},
+			input: `{"unknown": "A"}`,
+		},
+		{
+			desc:  "Invalid value",
+			dest:  &[]Test{},
+			input: `{"weight": "A"}`,
+		},
+		{
+			desc:  "Invalid JSON",
+			dest:  &[]Test{},
+			input: `{"letter": "A"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if err := DecodeNDJSON(strings.NewReader(tc.input), tc.dest); err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// KeyValues scans rows of two columns, a key and a value, into the fields of dest (a pointer to a struct)
+// mapped to the keys, as stored in attribute/value tables like "SELECT key, value FROM settings".
+//
+// Values are converted to the fields' types by database/sql, so text values can be scanned into
+// numbers and booleans. Keys that aren't mapped return an error, unless AllowUnknownColumns is used,
+// and fields whose key isn't present are left untouched.
+func KeyValues(dest interface{}, rows *sql.Rows, opts ...Option) error {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	value, err := destValue(dest)
+	if err != nil {
+		return err
+	}
+	if value.Kind() != reflect.Struct {
+		return codeErrorf(CodeInvalidDest, "dest must be a pointer to a struct")
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return o.noColumns()
+	}
+	if len(columns) != 2 {
+		return errorf("key/value rows must have 2 columns, got %d", len(columns))
+	}
+
+	mapping, ambiguous, err := mapType(value.Type(), o.naming)
+	if err != nil {
+		return err
+	}
+
+	var key string
+	for o.next(rows) {
+		// Scan the key first to know which field receives the value
+		if err := rows.Scan(&key, discard{}); err != nil {
+			return hookScanRow(err)
+		}
+		if o.normalize != nil {
+			key = o.normalize(key)
+		}
+
+		f, ok := o.lookup(mapping, key)
+		if !ok {
+			if err := ambiguous[key]; err != nil {
+				return err
+			}
+			if o.allowUnknown {
+				continue
+			}
+			return codeErrorf(CodeUnmappedColumn, "couldn't find a field for key %q", key)
+		}
+
+		allocNilPointers(value, f.index)
+		if err := rows.Scan(discard{}, value.FieldByIndex(f.index).Addr().Interface()); err != nil {
+			return hookScanRow(errorf("key %q: %w", key, err))
+		}
+		if err := hookScanRow(validate(value, []string{key}, []*field{f}, o)); err != nil {
+			return err
+		}
+	}
+
+	return o.err(rows)
+}
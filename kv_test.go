@@ -0,0 +1,60 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyValues(t *testing.T) {
+	expected := Test{Letter: "A", Weight: 100, Lowercase: true, Sub: Sub{Exported: true}}
+
+	rows, err := db.Query(`SELECT * FROM (VALUES
+	('letter', 'A'), ('weight', '100'), ('lower_case', 'true'), ('exported', 't')
+	) AS settings(key, value)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Test
+	if err := KeyValues(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestKeyValuesErrors(t *testing.T) {
+	cases := []struct {
+		desc  string
+		query string
+	}{
+		{
+			desc:  "Unknown key",
+			query: "SELECT 'unknown', 'value'",
+		},
+		{
+			desc:  "Invalid value",
+			query: "SELECT 'weight', 'heavy'",
+		},
+		{
+			desc:  "Too many columns",
+			query: "SELECT 'letter', 'A', 'B'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got Test
+			if err := KeyValues(&got, rows); err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package sqan
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// LimitError is returned by LimitStrict, or in strict mode, when the result has more rows than the limit.
+// It matches ErrTooManyRows with errors.Is.
+type LimitError struct {
+	// Limit is the maximum number of rows
+	Limit int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf(translate("the result has more than %d rows"), e.Limit)
+}
+
+// Is reports whether target is ErrTooManyRows.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrTooManyRows
+}
+
+// Limit stops scanning the rows after the first n, the rest of the result is ignored. In strict mode
+// a *LimitError is returned instead if there are more. It protects services from unbounded queries
+// materializing huge slices.
+func Limit(n int) Option {
+	return func(o *options) {
+		o.limit = n
+	}
+}
+
+// LimitStrict is like Limit but it returns a *LimitError if the result has more than n rows, without
+// enabling the rest of the checks of Strict.
+func LimitStrict(n int) Option {
+	return func(o *options) {
+		o.limit = n
+		o.limitStrict = true
+	}
+}
+
+// limitReached returns whether the rows scanned reached the limit, flagging the result as too long
+// with LimitStrict or in strict mode if there are more rows.
+func (o *options) limitReached(rows *sql.Rows) bool {
+	if o.limit <= 0 || o.fetched < o.limit {
+		return false
+	}
+	if (o.strict || o.limitStrict) && rows.Next() {
+		o.exceeded = true
+	}
+	return true
+}
+
+// err returns the error that ended the iteration of rows, if any.
+func (o *options) err(rows *sql.Rows) error {
+	if o.hookErr != nil {
+		return o.hookErr
+	}
+	if o.exceeded {
+		return &LimitError{Limit: o.limit}
+	}
+	return rows.Err()
+}
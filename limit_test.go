@@ -0,0 +1,68 @@
+package sqan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLimit(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	if err := Rows(&got, rows, Limit(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records[:2], got) {
+		t.Errorf("Expected %v, got %v", records[:2], got)
+	}
+
+	t.Run("Strict", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = All[Test](rows, Limit(2), Strict())
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != 2 {
+			t.Fatalf("Expected a limit error, got %v", err)
+		}
+		if !errors.Is(err, ErrTooManyRows) {
+			t.Error("Expected the limit error to match ErrTooManyRows")
+		}
+	})
+
+	t.Run("LimitStrict", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Unlike with Strict, the fields not populated by any column aren't an error
+		_, err = All[Test](rows, LimitStrict(2))
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != 2 {
+			t.Fatalf("Expected a limit error, got %v", err)
+		}
+	})
+
+	t.Run("Exact", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := All[Test](rows, Limit(len(records)), Strict())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(records, got) {
+			t.Errorf("Expected %v, got %v", records, got)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// Mapper scans rows into values of type T using a mapping computed when it's created.
+//
+// Unlike the functions of the package, it doesn't use the global mapping cache, so it can be built
+// and validated at startup and shared by goroutines without contention. It's safe for concurrent use
+// as long as its options don't share state, like CollectStats and Report do.
+type Mapper[T any] struct {
+	mapping   map[string]*field
+	ambiguous map[string]error
+	opts      []Option
+}
+
+// NewMapper returns a mapper for T, a struct or a pointer to a struct, that scans the rows with the
+// options provided. It returns an error if T can't be mapped.
+func NewMapper[T any](opts ...Option) (*Mapper[T], error) {
+	t := typeOf[T]()
+	if err := checkPointerLevels("type parameter", t); err != nil {
+		return nil, err
+	}
+	bType := baseType(t)
+	if bType.Kind() != reflect.Struct || isScannable(bType) {
+		return nil, codeErrorf(CodeInvalidDest, "type parameter must be a struct or a pointer to one, got %s", t)
+	}
+
+	mapping, ambiguous := make(map[string]*field), make(map[string]error)
+	if err := mapFields(bType, mapping, ambiguous, newOptions(opts).naming.resolve()); err != nil {
+		return nil, err
+	}
+
+	return &Mapper[T]{mapping: mapping, ambiguous: ambiguous, opts: opts}, nil
+}
+
+// ScanRow scans the first row into a value of type T and closes rows. It returns sql.ErrNoRows
+// if there are no rows.
+func (m *Mapper[T]) ScanRow(rows *sql.Rows) (T, error) {
+	defer rows.Close()
+	o := m.options()
+	defer o.done()
+
+	var result T
+	st, err := newStream[T](rows, o)
+	if err != nil {
+		return result, err
+	}
+
+	v, ok, err := st.next()
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		return result, sql.ErrNoRows
+	}
+	return v, nil
+}
+
+// ScanAll scans the rows into a slice of values of type T.
+func (m *Mapper[T]) ScanAll(rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+	o := m.options()
+	defer o.done()
+
+	return collect[T](rows, o)
+}
+
+// With returns a mapper that shares the mapping of m and scans with its options followed by opts, which
+// override them. It's cheap, so it can be used for per-request settings like Strict.
+//
+// Options that change the naming of the fields, like TagName and NameMapping, have no effect as the
+// mapping isn't computed again.
+func (m *Mapper[T]) With(opts ...Option) *Mapper[T] {
+	merged := make([]Option, 0, len(m.opts)+len(opts))
+	merged = append(merged, m.opts...)
+	merged = append(merged, opts...)
+	return &Mapper[T]{mapping: m.mapping, ambiguous: m.ambiguous, opts: merged}
+}
+
+func (m *Mapper[T]) options() *options {
+	o := newOptions(m.opts)
+	o.mapping, o.ambiguous = m.mapping, m.ambiguous
+	return o
+}
@@ -0,0 +1,78 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMapper(t *testing.T) {
+	mapper, err := NewMapper[Test]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ScanRow", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := mapper.ScanRow(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(records[0], got) {
+			t.Errorf("Expected %v, got %v", records[0], got)
+		}
+	})
+
+	t.Run("ScanAll", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := mapper.ScanAll(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(records, got) {
+			t.Errorf("Expected %v, got %v", records, got)
+		}
+	})
+
+	t.Run("With", func(t *testing.T) {
+		strict := mapper.With(Strict())
+
+		rows, err := db.Query("SELECT letter FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := strict.ScanAll(rows); ErrorCode(err) != CodeUnsetField {
+			t.Errorf("Expected an unset field error, got %v", err)
+		}
+
+		rows, err = db.Query("SELECT letter FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mapper.ScanAll(rows); err != nil {
+			t.Errorf("Expected the options of the original mapper to be unchanged, got %v", err)
+		}
+	})
+}
+
+func TestNewMapperErrors(t *testing.T) {
+	if _, err := NewMapper[int](); err == nil {
+		t.Error("Expected an error mapping a non-struct type")
+	}
+
+	type invalidOrd struct {
+		Name string `db:"name,ord=0"`
+	}
+	if _, err := NewMapper[invalidOrd](); err == nil {
+		t.Error("Expected an error mapping an invalid ord")
+	}
+}
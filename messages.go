@@ -0,0 +1,48 @@
+package sqan
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	// [English format]: translated format
+	messages   map[string]string
+	messagesMu sync.RWMutex
+)
+
+// SetMessages registers a catalog with the translations of the error messages, keyed by their English format
+// string as found in the source code, like "couldn't find a field for column %q". Messages without a
+// translation are returned in English and passing nil restores them all.
+//
+// Translations must keep the verbs of the original format in the same order, including %w, so
+// errors.Is and errors.As keep working.
+func SetMessages(catalog map[string]string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+
+	messages = catalog
+}
+
+// translate returns the translation of format if there is one.
+func translate(format string) string {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	if t, ok := messages[format]; ok {
+		return t
+	}
+	return format
+}
+
+// errorf is like fmt.Errorf but it uses the translation of format.
+func errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(translate(format), args...)
+}
+
+// message is an error whose text is translated when it's read, used for sentinel errors.
+type message string
+
+func (m message) Error() string {
+	return translate(string(m))
+}
@@ -0,0 +1,26 @@
+package sqan
+
+import "testing"
+
+func TestSetMessages(t *testing.T) {
+	SetMessages(map[string]string{
+		"couldn't find a field for column %q": "no se encontró un campo para la columna %q",
+		"the result has no columns":           "el resultado no tiene columnas",
+	})
+	defer SetMessages(nil)
+
+	rows, err := db.Query("SELECT letter AS unknown FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	err = Rows(&got, rows)
+	if expected := `no se encontró un campo para la columna "unknown"`; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+
+	if expected := "el resultado no tiene columnas"; ErrNoColumns.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, ErrNoColumns.Error())
+	}
+}
@@ -0,0 +1,129 @@
+package sqan
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	// defaultTag is the key of the struct tag used when it's not set per call
+	defaultTag = "db"
+	// defaultName converts the names of the fields without a tag when it's not set per call, nil lowercases them
+	defaultName func(field string) string
+	namingMu    sync.RWMutex
+)
+
+// SetTagName sets the key of the struct tag that contains the column names, "db" by default, for
+// teams whose structs are tagged for other libraries. The TagName option overrides it per call.
+func SetTagName(name string) {
+	namingMu.Lock()
+	defer namingMu.Unlock()
+
+	if name == "" {
+		name = "db"
+	}
+	defaultTag = name
+}
+
+// SetNameMapper sets the function that converts the name of the fields without a tag into a column
+// name, by default it's lowercased. For example, a function converting CamelCase into snake_case makes
+// CreatedAt match created_at without tagging it. A nil fn restores the default.
+//
+// It's meant to be called once at startup, the mappings computed before are discarded. The NameMapping
+// option overrides it per call.
+func SetNameMapper(fn func(field string) string) {
+	// Hold the cache lock so no mapping is computed with the previous function while it's cleared
+	mu.Lock()
+	defer mu.Unlock()
+	namingMu.Lock()
+	defer namingMu.Unlock()
+
+	defaultName = fn
+	mappingCache = make(map[mappingKey]typeFields)
+}
+
+// naming configures how the fields are named after columns, the zero value uses the defaults.
+type naming struct {
+	// name converts the name of a field without a tag into a column name, nil lowercases it
+	name func(field string) string
+	// tag is the key of the struct tag containing the column names, the default if empty
+	tag string
+	// maxDepth is the maximum nesting depth of the fields mapped, unlimited if zero
+	maxDepth int
+	// json makes the fields without a column name in their tag use the name in their json tag
+	json bool
+}
+
+// resolve returns n with the defaults in the settings that aren't set.
+func (n naming) resolve() naming {
+	namingMu.RLock()
+	defer namingMu.RUnlock()
+
+	if n.tag == "" {
+		n.tag = defaultTag
+	}
+	if n.name == nil {
+		n.name = defaultName
+	}
+	return n
+}
+
+// column returns the name of the column that a field without a tag is mapped to.
+func (n naming) column(field string) string {
+	if n.name == nil {
+		return strings.ToLower(field)
+	}
+	return n.name(field)
+}
+
+// SnakeCase converts a field name into snake_case, keeping acronyms together: UserID becomes user_id
+// and HTTPServer http_server. Use it with NameMapping or SetNameMapper.
+func SnakeCase(field string) string {
+	return delimit(field, '_')
+}
+
+// Kebab converts a field name into kebab-case, like SnakeCase but separating the words with dashes.
+func Kebab(field string) string {
+	return delimit(field, '-')
+}
+
+// LowerCamel converts a field name into lowerCamelCase, lowercasing its first word: UserID becomes
+// userID and HTTPServer httpServer.
+func LowerCamel(field string) string {
+	runes := []rune(field)
+	for i := range runes {
+		// Keep the last letter of an acronym followed by a word, like the S of HTTPServer
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// Exact uses field names as they are, for columns named like the fields.
+func Exact(field string) string {
+	return field
+}
+
+// delimit lowercases the words of a field name and joins them with sep.
+func delimit(field string, sep rune) string {
+	runes := []rune(field)
+	var sb strings.Builder
+	sb.Grow(len(field) + 2)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteRune(sep)
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
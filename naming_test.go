@@ -0,0 +1,60 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	cases := []struct {
+		field      string
+		snake      string
+		kebab      string
+		lowerCamel string
+	}{
+		{field: "ID", snake: "id", kebab: "id", lowerCamel: "id"},
+		{field: "Name", snake: "name", kebab: "name", lowerCamel: "name"},
+		{field: "UserID", snake: "user_id", kebab: "user-id", lowerCamel: "userID"},
+		{field: "CreatedAt", snake: "created_at", kebab: "created-at", lowerCamel: "createdAt"},
+		{field: "HTTPServer", snake: "http_server", kebab: "http-server", lowerCamel: "httpServer"},
+		{field: "Address2Line", snake: "address2_line", kebab: "address2-line", lowerCamel: "address2Line"},
+		{field: "already_snake", snake: "already_snake", kebab: "already_snake", lowerCamel: "already_snake"},
+	}
+
+	for _, tc := range cases {
+		if got := SnakeCase(tc.field); got != tc.snake {
+			t.Errorf("SnakeCase(%q): expected %q, got %q", tc.field, tc.snake, got)
+		}
+		if got := Kebab(tc.field); got != tc.kebab {
+			t.Errorf("Kebab(%q): expected %q, got %q", tc.field, tc.kebab, got)
+		}
+		if got := LowerCamel(tc.field); got != tc.lowerCamel {
+			t.Errorf("LowerCamel(%q): expected %q, got %q", tc.field, tc.lowerCamel, got)
+		}
+		if got := Exact(tc.field); got != tc.field {
+			t.Errorf("Exact(%q): expected %q, got %q", tc.field, tc.field, got)
+		}
+	}
+}
+
+func TestSnakeCaseMapping(t *testing.T) {
+	type snake struct {
+		Letter    string
+		LowerCase bool
+	}
+	expected := []snake{{Letter: "A", LowerCase: false}, {Letter: "b", LowerCase: true}, {Letter: "C", LowerCase: false}}
+
+	rows, err := db.Query("SELECT letter, lower_case FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := All[snake](rows, NameMapping(SnakeCase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,82 @@
+package sqan
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// NullPolicy decides what happens when a NULL is scanned into a field that can't hold it, like a string
+// or an int.
+type NullPolicy int
+
+const (
+	// NullDriverError lets database/sql scan the value, which fails with an error that doesn't name the
+	// field. It's the default.
+	NullDriverError NullPolicy = iota
+	// NullZero sets the field to its zero value.
+	NullZero
+	// NullReject returns a *NullIntoNonPointerError naming the column and the field.
+	NullReject
+	// NullRequire returns an error before scanning if any column is scanned into a field that can't hold
+	// NULL, so that only pointers, sql.Null types and other scanners accepting NULL are used.
+	NullRequire
+)
+
+// NullIntoNonPointerError is returned with the NullReject policy when a NULL is scanned into a field that
+// can't hold it.
+type NullIntoNonPointerError struct {
+	Column string
+	Field  string
+}
+
+func (e *NullIntoNonPointerError) Error() string {
+	return fmt.Sprintf(translate("column %q is NULL but field %s can't hold NULL, use a pointer or a sql.Null type"), e.Column, e.Field)
+}
+
+// NullHandling sets what happens when a NULL is scanned into a field that can't hold it. NullZero and
+// NullReject scan the rows twice to find the NULL values first.
+func NullHandling(policy NullPolicy) Option {
+	return func(o *options) {
+		o.nulls = policy
+	}
+}
+
+// requireNullable returns an error if a column is scanned into a field that can't hold NULL.
+func requireNullable(columns []string, columnFields []*field) error {
+	for i, f := range columnFields {
+		if f != nil && !acceptsNull(f.typ) {
+			return codeErrorf(CodeNullable, "column %q is scanned into field %s of type %s, which can't hold NULL", columns[i], f.path, f.typ)
+		}
+	}
+	return nil
+}
+
+// handleNulls scans the row to find the NULL values of the fields that can't hold them and applies the null
+// policy, replacing their addresses in fields with discard if they are set to their zero value.
+func (o *options) handleNulls(rows *sql.Rows, fields []interface{}, v reflect.Value, columns []string, columnFields []*field) error {
+	if o.nulls != NullZero && o.nulls != NullReject {
+		return nil
+	}
+
+	probes := make([]nullProbe, len(columns))
+	probeFields := make([]interface{}, len(columns))
+	for i := range probes {
+		probeFields[i] = &probes[i]
+	}
+	if err := rows.Scan(probeFields...); err != nil {
+		return err
+	}
+
+	for i, f := range columnFields {
+		if f == nil || !probes[i].null || acceptsNull(f.typ) {
+			continue
+		}
+		if o.nulls == NullReject {
+			return &NullIntoNonPointerError{Column: columns[i], Field: f.path}
+		}
+		v.FieldByIndex(f.index).Set(reflect.Zero(f.typ))
+		fields[i] = discard{}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package sqan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNullHandling(t *testing.T) {
+	const query = "SELECT NULL::text AS letter, weight FROM tests ORDER BY weight"
+
+	t.Run("Zero", func(t *testing.T) {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := All[Test](rows, NullHandling(NullZero))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Test{{Weight: 0}, {Weight: 100}, {Weight: 200}}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("StrictZero", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, NULL::integer AS weight, lower_case, exported FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := All[Test](rows, Strict(), NullHandling(NullZero))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(records) || got[0].Letter != "A" || got[0].Weight != 0 {
+			t.Errorf("Expected %d rows with zero weights, got %v", len(records), got)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = All[Test](rows, NullHandling(NullReject))
+		var nullErr *NullIntoNonPointerError
+		if !errors.As(err, &nullErr) {
+			t.Fatalf("Expected a null error, got %v", err)
+		}
+		if nullErr.Column != "letter" || nullErr.Field != "Letter" {
+			t.Errorf("Expected column letter and field Letter, got %q and %q", nullErr.Column, nullErr.Field)
+		}
+		if code := ErrorCode(err); code != CodeNullable {
+			t.Errorf("Expected code %s, got %s", CodeNullable, code)
+		}
+	})
+
+	t.Run("Require", func(t *testing.T) {
+		type nullable struct {
+			Letter *string
+			Weight int
+		}
+
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := All[nullable](rows, NullHandling(NullRequire)); ErrorCode(err) != CodeNullable {
+			t.Errorf("Expected code %s, got %v", CodeNullable, err)
+		}
+
+		rows, err = db.Query("SELECT NULL::text AS letter FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := All[nullable](rows, NullHandling(NullRequire))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(records) || got[0].Letter != nil {
+			t.Errorf("Expected %d rows with nil letters, got %v", len(records), got)
+		}
+	})
+}
@@ -0,0 +1,382 @@
+package sqan
+
+import (
+	"database/sql"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Option configures the scanning of rows.
+type Option func(*options)
+
+type options struct {
+	normalize func(column string) string
+	// aliases maps column names to the paths of the fields they are scanned into
+	aliases map[string]string
+	// resume returns the rows following the one with the value of resumeKey passed
+	resume    func(lastKey interface{}) (*sql.Rows, error)
+	resumeKey string
+	// mapping and ambiguous are the mapping of the destination type, used instead of the cached one if not nil
+	mapping   map[string]*field
+	ambiguous map[string]error
+	naming    naming
+	report    *ScanReport
+	tag       string
+	warn      func(err error)
+	// traceWriter receives the decoding of the first traceRows rows, traced counts the rows written
+	traceWriter io.Writer
+	stats       *Stats
+	sample      *rand.Rand
+	start       time.Time
+	sampleSize  int
+	sizeHint    int
+	maxBytes    int64
+	// limit is the maximum number of rows scanned, fetched counts them and exceeded is set with
+	// limitStrict or in strict mode if the result has more
+	limit       int
+	limitStrict bool
+	fetched     int
+	exceeded    bool
+	// hookErr is the error returned by the afterNext test hook
+	hookErr         error
+	traceRows       int
+	traced          int
+	checkOrder      bool
+	strict          bool
+	allowUnknown    bool
+	duplicates      DuplicatePolicy
+	nulls           NullPolicy
+	sparse          bool
+	caseInsensitive bool
+	resetSlice      bool
+}
+
+// Stats reports how the time spent scanning rows was distributed.
+type Stats struct {
+	// Tag is the tag set with the Tag option.
+	Tag string
+	// Rows is the number of rows scanned.
+	Rows int
+	// Fetch is the time spent waiting for the driver to deliver rows (rows.Next).
+	Fetch time.Duration
+	// Decode is the time spent mapping and decoding the rows into the destination.
+	Decode time.Duration
+}
+
+// CollectStats populates s with the number of rows scanned and the time spent
+// fetching them from the database and decoding them.
+//
+// It's useful to tell whether a slow scan is caused by the database or by the
+// conversion of the values.
+func CollectStats(s *Stats) Option {
+	return func(o *options) {
+		o.stats = s
+	}
+}
+
+// Tag names the call, like "checkout.listOrders", to attribute its cost to the code that made it. The tag
+// is included in the Stats, the lines written by Trace and, for the functions executing queries, in the
+// context passed to the OnQuery function (see QueryTag) and the comments added by TraceComment.
+func Tag(name string) Option {
+	return func(o *options) {
+		o.tag = name
+	}
+}
+
+// CheckOrder verifies that the columns are in the position specified by the "ord" option of the
+// fields' db tag (starting from 1), for example `db:"name,ord=2"`. Fields without it aren't checked.
+//
+// It's meant to be used with "SELECT *" queries, to catch schema changes that shift the
+// values between fields of the same type.
+func CheckOrder() Option {
+	return func(o *options) {
+		o.checkOrder = true
+	}
+}
+
+// AllowUnknownColumns skips the columns that don't match any field instead of returning an error,
+// for "SELECT *" queries on tables that have columns the destination doesn't need.
+func AllowUnknownColumns() Option {
+	return func(o *options) {
+		o.allowUnknown = true
+	}
+}
+
+// CaseInsensitive matches the columns that don't have the exact name of a field ignoring case, so "LETTER",
+// "Letter" and "letter" are all scanned into the field mapped to "letter". It's useful with databases
+// that return upper-cased column names, like Oracle. If several fields match, the first one declared is used.
+func CaseInsensitive() Option {
+	return func(o *options) {
+		o.caseInsensitive = true
+	}
+}
+
+// ResetSlice makes Rows discard the elements the destination slice already has instead of appending
+// the rows to them, reusing its capacity. It's useful when a slice is reused as a buffer between queries.
+func ResetSlice() Option {
+	return func(o *options) {
+		o.resetSlice = true
+	}
+}
+
+// DuplicatePolicy decides how the columns that appear more than once in a result are scanned, which is
+// common with queries like "SELECT a.*, b.*".
+type DuplicatePolicy int
+
+const (
+	// DuplicateAll scans all the occurrences of a column into its field, so it holds the value of the
+	// last one. It's the default.
+	DuplicateAll DuplicatePolicy = iota
+	// DuplicateError returns an error if a column appears more than once. Columns scanned into the same
+	// field are duplicates even if their names differ, like "ID" and "id" with CaseInsensitive.
+	DuplicateError
+	// DuplicateFirst scans the first occurrence of a column and skips the rest.
+	DuplicateFirst
+	// DuplicatePositional matches the n-th occurrence of a column, from the second one, with the field
+	// mapped to the column name followed by "_n". For example, the second "id" column is scanned into the
+	// field mapped to "id_2".
+	DuplicatePositional
+)
+
+// DuplicateColumns sets how the columns that appear more than once in a result are scanned.
+func DuplicateColumns(policy DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicates = policy
+	}
+}
+
+// Strict enables checks that catch likely mistakes in the queries or the destinations:
+//
+//   - Results without columns return ErrNoColumns instead of being ignored, it usually means
+//     the rows come from a statement that doesn't return values.
+//   - Values must satisfy the assertions of their fields' tag, otherwise an *AssertionError is returned:
+//     min=N and max=N set the bounds of numbers and nonempty rejects zero values, like empty strings
+//     and NULL, for example `db:"age,min=0,max=150"`.
+//   - Columns must have the database type set in the "dbtype" option of their field's tag, like
+//     `db:"amount,dbtype=NUMERIC"`, to catch schema changes. It's checked only if the driver reports
+//     the types, names are compared case-insensitively.
+//   - All the fields must be populated by a column, to catch typos in the tags and outdated structs,
+//     except those with the "optional" tag option (or whose parent has it), like `db:"new_col,optional"`.
+//   - Columns the driver reports as nullable can't be scanned into fields that don't accept NULL,
+//     like strings and integers, see OnWarning.
+//   - Results with more rows than the limit set with Limit return a *LimitError instead of being truncated,
+//     like with LimitStrict.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// OnWarning calls fn with the problems found in the mapping that don't prevent the scanning but may
+// make it fail later, like a column the driver reports as nullable whose field isn't a pointer or a
+// sql.Scanner, which fails only once a NULL is returned. In strict mode they are returned as errors instead.
+//
+// fn is called once per query for each problem, before scanning the rows.
+func OnWarning(fn func(err error)) Option {
+	return func(o *options) {
+		o.warn = fn
+	}
+}
+
+// Sample makes Rows and All return a uniform random sample of n rows of the result, chosen while
+// scanning it (reservoir sampling) instead of sorting the table randomly in the database. Rows that
+// aren't part of the sample aren't decoded.
+//
+// The same seed selects the same rows from the same result. The sampled rows aren't returned in the
+// order of the result.
+func Sample(n int, seed int64) Option {
+	return func(o *options) {
+		o.sampleSize = n
+		o.sample = rand.New(rand.NewSource(seed))
+	}
+}
+
+// SizeHint makes Rows and All reserve room for n rows before appending the first one, to avoid growing
+// the slice repeatedly when scanning large results of a known size. database/sql doesn't report the
+// number of rows of a result, so n is typically taken from a previous COUNT(*) or from the statistics
+// of the table, like reltuples in PostgreSQL. It's capped by Limit and Sample.
+func SizeHint(n int) Option {
+	return func(o *options) {
+		o.sizeHint = n
+	}
+}
+
+// TagName sets the key of the struct tag that contains the column names, "db" by default.
+func TagName(name string) Option {
+	return func(o *options) {
+		o.naming.tag = name
+	}
+}
+
+// JSONTags makes the fields without a column name in their db tag use the name in their json tag, for
+// structs already tagged with the names of the columns for encoding/json. For example, a field tagged
+// `json:"created_at,omitempty"` is mapped to the "created_at" column. Fields ignored by encoding/json
+// with `json:"-"` are named as if they had no tag.
+func JSONTags() Option {
+	return func(o *options) {
+		o.naming.json = true
+	}
+}
+
+// MaxDepth limits the mapping to the fields nested up to n levels, the fields of the destination struct
+// being the first one. The fields of deeper structs aren't mapped, so their columns are unknown.
+//
+// Struct types containing themselves, like `type Node struct { Parent *Node }`, are handled regardless
+// of the limit: the fields of a type that is already being mapped aren't mapped again.
+func MaxDepth(n int) Option {
+	return func(o *options) {
+		o.naming.maxDepth = n
+	}
+}
+
+// NameMapping sets the function that converts the name of the fields without a tag into a column
+// name, by default it's lowercased. Mappings using it are computed on each call instead of cached.
+func NameMapping(fn func(field string) string) Option {
+	return func(o *options) {
+		o.naming.name = fn
+	}
+}
+
+// NormalizeColumns rewrites the names of the columns returned by the database before matching
+// them with the destination fields. Columns whose name is rewritten to an empty string are skipped.
+//
+// CleanColumn can be used for the most common cases.
+func NormalizeColumns(fn func(column string) string) Option {
+	return func(o *options) {
+		o.normalize = fn
+	}
+}
+
+// Aliases scans the columns in the map keys into the fields in the values, overriding the mapping for a
+// single call, so that columns with odd names can be scanned without tagging shared structs or renaming
+// them in the query. Fields are named by their path, like Name or Address.Street, or by their column.
+//
+//	sqan.Rows(&users, rows, sqan.Aliases(map[string]string{"usr_nm": "Username"}))
+//
+// Columns are looked up after being normalized by NormalizeColumns.
+func Aliases(aliases map[string]string) Option {
+	return func(o *options) {
+		o.aliases = aliases
+	}
+}
+
+// CleanColumn normalizes column names returned by drivers for expressions and qualified columns.
+//
+// It drops unnamed expression columns ("?column?"), removes schema and table prefixes
+// ("public.users.name" becomes "name"), strips identifier quotes and collapses whitespace.
+func CleanColumn(column string) string {
+	column = strings.Join(strings.Fields(column), " ")
+	if column == "?column?" {
+		return ""
+	}
+	if i := strings.LastIndexByte(column, '.'); i != -1 {
+		column = column[i+1:]
+	}
+	return strings.Trim(column, "\"`[] ")
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.stats != nil {
+		*o.stats = Stats{Tag: o.tag}
+		o.start = time.Now()
+	}
+	return o
+}
+
+// next advances rows measuring the time it takes if stats are being collected. It returns false once
+// the limit of rows is reached.
+func (o *options) next(rows *sql.Rows) bool {
+	if o.limitReached(rows) {
+		return false
+	}
+	o.fetched++
+
+	var ok bool
+	if o.stats == nil {
+		ok = rows.Next()
+	} else {
+		start := time.Now()
+		ok = rows.Next()
+		o.stats.Fetch += time.Since(start)
+		if ok {
+			o.stats.Rows++
+		}
+	}
+
+	if ok {
+		if o.hookErr = hookNext(); o.hookErr != nil {
+			return false
+		}
+	}
+	return ok
+}
+
+// slot returns the position of the i-th row (starting from 0) in the result, which is i unless sampling,
+// or -1 if the row must be skipped.
+func (o *options) slot(i int) int {
+	if o.sample == nil || i < o.sampleSize {
+		return i
+	}
+	if j := o.sample.Intn(i + 1); j < o.sampleSize {
+		return j
+	}
+	return -1
+}
+
+// capacity returns the number of rows to reserve room for, zero if there is no hint.
+func (o *options) capacity() int {
+	n := o.sizeHint
+	if o.limit > 0 && o.limit < n {
+		n = o.limit
+	}
+	if o.sample != nil && o.sampleSize < n {
+		n = o.sampleSize
+	}
+	return max(n, 0)
+}
+
+// lookup returns the field in mapping that column is scanned into.
+func (o *options) lookup(mapping map[string]*field, column string) (*field, bool) {
+	if alias, ok := o.aliases[column]; ok {
+		for _, f := range mapping {
+			if f.path == alias {
+				return f, true
+			}
+		}
+		column = alias
+	}
+
+	if f, ok := mapping[column]; ok || !o.caseInsensitive {
+		return f, ok
+	}
+
+	var match *field
+	for name, f := range mapping {
+		if strings.EqualFold(name, column) && (match == nil || lessIndex(f.index, match.index)) {
+			match = f
+		}
+	}
+	return match, match != nil
+}
+
+// noColumns returns the error for results without columns, which is nil unless in strict mode.
+func (o *options) noColumns() error {
+	if o.strict {
+		return ErrNoColumns
+	}
+	return nil
+}
+
+// done records the decoding time, it's everything that wasn't spent fetching rows.
+func (o *options) done() {
+	if o.stats == nil {
+		return
+	}
+	o.stats.Decode = time.Since(o.start) - o.stats.Fetch
+}
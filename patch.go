@@ -0,0 +1,160 @@
+package sqan
+
+import (
+	"database/sql"
+	"math"
+	"reflect"
+	"time"
+)
+
+// ApplyPatch sets the fields of dest, a pointer to a struct, mapped to the columns in patch.
+//
+// Values are converted to the type of the fields: numbers are converted between types as long
+// as they aren't truncated (like JSON's float64 into an int), strings in RFC 3339 format are
+// parsed into times, types implementing sql.Scanner receive the value through Scan and nil
+// sets the zero value. If a column isn't mapped or a value can't be converted, an error is
+// returned and dest is left unmodified.
+func ApplyPatch(dest interface{}, patch map[string]interface{}) error {
+	value, err := destValue(dest)
+	if err != nil {
+		return err
+	}
+	if value.Kind() != reflect.Struct {
+		return codeErrorf(CodeInvalidDest, "dest must be a pointer to a struct")
+	}
+
+	mapping, err := typeMapping(value.Type(), naming{})
+	if err != nil {
+		return err
+	}
+
+	// Convert all the values before modifying dest
+	fields := make([]*field, 0, len(patch))
+	values := make([]reflect.Value, 0, len(patch))
+	for c, v := range patch {
+		f, ok := mapping[c]
+		if !ok {
+			return codeErrorf(CodeUnmappedColumn, "couldn't find a field for column %q", c)
+		}
+
+		converted, err := convertValue(v, f.typ)
+		if err != nil {
+			return errorf("column %q: %w", c, err)
+		}
+
+		fields = append(fields, f)
+		values = append(values, converted)
+	}
+
+	for i, f := range fields {
+		allocNilPointers(value, f.index)
+		value.FieldByIndex(f.index).Set(values[i])
+	}
+
+	return nil
+}
+
+// convertValue converts src into a value of type t.
+func convertValue(src interface{}, t reflect.Type) (reflect.Value, error) {
+	if src == nil {
+		return reflect.Zero(t), nil
+	}
+
+	if reflect.PtrTo(t).Implements(_scannerInterface) {
+		v := reflect.New(t)
+		if err := v.Interface().(sql.Scanner).Scan(src); err != nil {
+			return reflect.Value{}, err
+		}
+		return v.Elem(), nil
+	}
+
+	if t.Kind() == reflect.Ptr {
+		elem, err := convertValue(src, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(t.Elem())
+		v.Elem().Set(elem)
+		return v, nil
+	}
+
+	sv := reflect.ValueOf(src)
+	switch {
+	case sv.Type().AssignableTo(t):
+		return sv, nil
+	case isNumber(sv.Kind()) && isNumber(t.Kind()):
+		return convertNumber(sv, t)
+	case sv.Kind() == t.Kind() && sv.Type().ConvertibleTo(t):
+		return sv.Convert(t), nil
+	case t == _timeType && sv.Kind() == reflect.String:
+		tm, err := time.Parse(time.RFC3339Nano, sv.String())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(tm), nil
+	}
+
+	return reflect.Value{}, errorf("can't convert %T to %s", src, t)
+}
+
+// convertNumber converts the number v into type t, failing if it doesn't fit or it's truncated.
+func convertNumber(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	result := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return reflect.Value{}, errorf("%v doesn't fit in %s", f, t)
+			}
+			n = int64(f)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v.Uint() > math.MaxInt64 {
+				return reflect.Value{}, errorf("%v doesn't fit in %s", v.Uint(), t)
+			}
+			n = int64(v.Uint())
+		default:
+			n = v.Int()
+		}
+		if result.OverflowInt(n) {
+			return reflect.Value{}, errorf("%v doesn't fit in %s", n, t)
+		}
+		result.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return reflect.Value{}, errorf("%v doesn't fit in %s", f, t)
+			}
+			n = uint64(f)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.Int() < 0 {
+				return reflect.Value{}, errorf("%v doesn't fit in %s", v.Int(), t)
+			}
+			n = uint64(v.Int())
+		default:
+			n = v.Uint()
+		}
+		if result.OverflowUint(n) {
+			return reflect.Value{}, errorf("%v doesn't fit in %s", n, t)
+		}
+		result.SetUint(n)
+	default:
+		result.Set(v.Convert(t))
+	}
+	return result, nil
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
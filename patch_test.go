@@ -0,0 +1,60 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	got := records[0]
+	expected := got
+	expected.Letter = "Z"
+	expected.Weight = 150
+	expected.Sub.Exported = false
+
+	patch := map[string]interface{}{
+		"letter":   "Z",
+		"weight":   float64(150), // As decoded by encoding/json
+		"exported": false,
+	}
+	if err := ApplyPatch(&got, patch); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	cases := []struct {
+		patch map[string]interface{}
+		desc  string
+	}{
+		{
+			desc:  "Unknown column",
+			patch: map[string]interface{}{"unknown": 1},
+		},
+		{
+			desc:  "Truncated number",
+			patch: map[string]interface{}{"letter": "Z", "weight": 1.5},
+		},
+		{
+			desc:  "Invalid type",
+			patch: map[string]interface{}{"letter": "Z", "lower_case": "yes"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := records[0]
+			if err := ApplyPatch(&got, tc.patch); err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+
+			if !reflect.DeepEqual(records[0], got) {
+				t.Errorf("Expected dest to be unmodified, got %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// Pivot scans the values of column v into a map keyed by the values of columns r and c, the rows and
+// columns of a cross-tab report. Other columns are ignored.
+//
+// A pair of r and c values appearing more than once returns an error, as one of the values would be lost.
+func Pivot[R, C comparable, V any](rows *sql.Rows, r, c, v string, opts ...Option) (map[R]map[C]V, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		row    R
+		col    C
+		value  V
+		fields = make([]interface{}, len(columns))
+	)
+	for i := range fields {
+		fields[i] = discard{}
+	}
+	for _, p := range []struct {
+		name string
+		dest interface{}
+	}{{r, &row}, {c, &col}, {v, &value}} {
+		i := columnIndex(columns, p.name, o)
+		if i == -1 {
+			return nil, errorf("column %q not found", p.name)
+		}
+		if _, ok := fields[i].(discard); !ok {
+			return nil, errorf("column %q is used more than once", p.name)
+		}
+		fields[i] = p.dest
+	}
+
+	// The keys of the rows are counted once, the columns and values for each row
+	var used int64
+	rowValue, colValue, valueValue := reflect.ValueOf(&row).Elem(), reflect.ValueOf(&col).Elem(), reflect.ValueOf(&value).Elem()
+	result := make(map[R]map[C]V)
+	for i := 1; o.next(rows); i++ {
+		if err := hookScanRow(rows.Scan(fields...)); err != nil {
+			return nil, err
+		}
+
+		m, ok := result[row]
+		if !ok {
+			if err := o.countBytes(&used, rowValue, i); err != nil {
+				return nil, err
+			}
+			m = make(map[C]V)
+			result[row] = m
+		}
+		if _, ok := m[col]; ok {
+			return nil, errorf("duplicate value for %v, %v", row, col)
+		}
+		if err := o.countBytes(&used, colValue, i); err != nil {
+			return nil, err
+		}
+		if err := o.countBytes(&used, valueValue, i); err != nil {
+			return nil, err
+		}
+		m[col] = value
+	}
+
+	return result, o.err(rows)
+}
+
+// columnIndex returns the position of the column with the name provided or -1 if it's not present.
+func columnIndex(columns []string, name string, o *options) int {
+	for i, c := range columns {
+		if o.normalize != nil {
+			c = o.normalize(c)
+		}
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,55 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPivot(t *testing.T) {
+	expected := map[bool]map[string]int{
+		false: {"A": 100, "C": 200},
+		true:  {"b": 0},
+	}
+	rows, err := db.Query("SELECT letter, weight, lower_case, exported FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Pivot[bool, string, int](rows, "lower_case", "letter", "weight")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestPivotErrors(t *testing.T) {
+	cases := []struct {
+		desc  string
+		query string
+	}{
+		{
+			desc:  "Missing column",
+			query: "SELECT letter, weight FROM tests",
+		},
+		{
+			desc:  "Duplicate pair",
+			query: "SELECT lower_case, exported AS letter, weight FROM tests",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Pivot[bool, string, int](rows, "lower_case", "letter", "weight"); err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+)
+
+// Poly scans each row into the type registered for the value of its discriminator column,
+// for tables storing different types of objects (single-table inheritance).
+//
+// The functions in types must return pointers to structs, which are also the elements of
+// the slice returned. T can be any or an interface implemented by all the types. Columns
+// that the type of a row doesn't map are skipped, as each type usually uses a subset of them,
+// but in strict mode the columns that none of the types map return an error, unless
+// AllowUnknownColumns is used.
+func Poly[T any](rows *sql.Rows, column string, types map[string]func() T, opts ...Option) ([]T, error) {
+	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	raw, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columns := raw
+	if o.normalize != nil {
+		columns = make([]string, len(raw))
+		for i, c := range raw {
+			columns[i] = o.normalize(c)
+		}
+	}
+
+	discriminator := -1
+	for i, c := range columns {
+		if c == column {
+			discriminator = i
+			break
+		}
+	}
+	if discriminator == -1 {
+		return nil, errorf("discriminator column %q not found", column)
+	}
+	if o.strict && !o.allowUnknown {
+		if err := checkPolyColumns(columns, discriminator, types, o); err != nil {
+			return nil, err
+		}
+	}
+
+	// Each type is mapped like in All, but it usually maps a subset of the columns
+	typeOpts := *o
+	typeOpts.allowUnknown = true
+
+	var (
+		result []T
+		used   int64
+		kind   string
+		// [concrete type]: column fields
+		plans = make(map[reflect.Type][]*field)
+		// [concrete type]: types of the columns scanned into interface{} fields
+		decodes = make(map[reflect.Type][]reflect.Type)
+		// [concrete type]: pointers to nested structs left nil with SparseJoins
+		pointers = make(map[reflect.Type][]sparsePointer)
+		fields   = make([]interface{}, len(columns))
+	)
+	for o.next(rows) {
+		for i := range fields {
+			fields[i] = discard{}
+		}
+		fields[discriminator] = &kind
+		if err := rows.Scan(fields...); err != nil {
+			return nil, hookScanRow(err)
+		}
+
+		newT, ok := types[kind]
+		if !ok {
+			return nil, errorf("no type registered for %s %q", column, kind)
+		}
+		obj := newT()
+
+		vPtr := reflect.ValueOf(obj)
+		if vPtr.Kind() != reflect.Ptr || vPtr.IsNil() || vPtr.Elem().Kind() != reflect.Struct {
+			return nil, codeErrorf(CodeInvalidDest, "type registered for %s %q must be a non-nil pointer to a struct, got %T", column, kind, obj)
+		}
+		v := vPtr.Elem()
+
+		columnFields, ok := plans[v.Type()]
+		if !ok {
+			if columnFields, err = columnsFields(v.Type(), rows, raw, &typeOpts); err != nil {
+				return nil, err
+			}
+			plans[v.Type()] = columnFields
+			decodes[v.Type()] = decodeTypes(rows, columnFields)
+			if o.sparse {
+				pointers[v.Type()] = sparsePointers(v.Type(), columnFields)
+			}
+		}
+
+		columnFields, err := o.sparseColumns(rows, v, columnFields, pointers[v.Type()])
+		if err != nil {
+			return nil, hookScanRow(err)
+		}
+		fieldsAddrs(fields, v, columnFields, decodes[v.Type()])
+		err = o.handleNulls(rows, fields, v, columns, columnFields)
+		if err == nil {
+			err = rows.Scan(fields...)
+		}
+		if err == nil {
+			err = validate(v, raw, columnFields, o)
+		}
+		if err := hookScanRow(err); err != nil {
+			return nil, err
+		}
+		if err := o.countBytes(&used, vPtr, len(result)+1); err != nil {
+			return nil, err
+		}
+		result = append(result, obj)
+	}
+
+	return result, o.err(rows)
+}
+
+// checkPolyColumns returns an error if a column other than the discriminator isn't mapped by any of the
+// types. Functions returning invalid types are skipped, they are reported when scanning.
+func checkPolyColumns[T any](columns []string, discriminator int, types map[string]func() T, o *options) error {
+	mapped := make([]bool, len(columns))
+	mapped[discriminator] = true
+	for _, newT := range types {
+		t := reflect.TypeOf(newT())
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		mapping, ambiguous, err := mapType(t.Elem(), o.naming)
+		if err != nil {
+			return err
+		}
+		for i, c := range columns {
+			// Ambiguous columns are reported when scanning the types naming them
+			if _, ok := o.lookup(mapping, c); ok || ambiguous[c] != nil || c == "" {
+				mapped[i] = true
+			}
+		}
+	}
+
+	for i, ok := range mapped {
+		if !ok {
+			return codeErrorf(CodeUnmappedColumn, "couldn't find a field for column %q in any of the types", columns[i])
+		}
+	}
+	return nil
+}
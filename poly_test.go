@@ -0,0 +1,138 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+type letter interface {
+	Char() string
+}
+
+type lowerLetter struct {
+	Letter string
+	Weight int
+}
+
+func (l *lowerLetter) Char() string { return l.Letter }
+
+type upperLetter struct {
+	Letter   string
+	Kind     string
+	Exported bool
+}
+
+func (u *upperLetter) Char() string { return u.Letter }
+
+func TestPoly(t *testing.T) {
+	expected := []letter{
+		&upperLetter{Letter: "A", Kind: "upper", Exported: true},
+		&lowerLetter{Letter: "b", Weight: 0},
+		&upperLetter{Letter: "C", Kind: "upper", Exported: true},
+	}
+	rows, err := db.Query(`SELECT letter, weight, exported,
+	CASE WHEN lower_case THEN 'lower' ELSE 'upper' END AS kind
+	FROM tests`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Poly(rows, "kind", map[string]func() letter{
+		"lower": func() letter { return &lowerLetter{} },
+		"upper": func() letter { return &upperLetter{} },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	t.Run("Strict", func(t *testing.T) {
+		types := map[string]func() letter{
+			"lower": func() letter { return &lowerLetter{} },
+			"upper": func() letter { return &upperLetter{} },
+		}
+		query := `SELECT letter, weight, exported, lower_case,
+		CASE WHEN lower_case THEN 'lower' ELSE 'upper' END AS kind
+		FROM tests`
+
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Poly(rows, "kind", types, Strict()); ErrorCode(err) != CodeUnmappedColumn {
+			t.Errorf("Expected code %s, got %v", CodeUnmappedColumn, err)
+		}
+
+		rows, err = db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Poly(rows, "kind", types, Strict(), AllowUnknownColumns())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, 'lower' AS kind FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var report ScanReport
+		_, err = Poly(rows, "kind", map[string]func() letter{
+			"lower": func() letter { return &lowerLetter{} },
+		}, Report(&report))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Columns) != 2 {
+			t.Errorf("Expected 2 columns in the report, got %+v", report)
+		}
+	})
+}
+
+func TestPolyErrors(t *testing.T) {
+	cases := []struct {
+		types map[string]func() interface{}
+		desc  string
+		query string
+	}{
+		{
+			desc:  "Missing discriminator",
+			query: "SELECT letter FROM tests",
+			types: map[string]func() interface{}{},
+		},
+		{
+			desc:  "Unregistered type",
+			query: "SELECT letter, 'unknown' AS kind FROM tests",
+			types: map[string]func() interface{}{},
+		},
+		{
+			desc:  "Not a pointer",
+			query: "SELECT letter, 'upper' AS kind FROM tests",
+			types: map[string]func() interface{}{
+				"upper": func() interface{} { return upperLetter{} },
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Poly(rows, "kind", tc.types); err == nil {
+				t.Fatal("Expected an error and got nil")
+			}
+		})
+	}
+}
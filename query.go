@@ -0,0 +1,130 @@
+package sqan
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	// queryHook rewrites the queries executed by the package, nil if not set
+	queryHook   func(ctx context.Context, query string, args []interface{}) (string, []interface{}, error)
+	queryHookMu sync.RWMutex
+)
+
+// OnQuery sets a function that is called before the functions of the package execute a query, like Query,
+// Backfill, SelectSharded, SelectHedged and CompareQueries, with the query and its arguments. The query
+// executed is the one it returns, so it can append comments, enforce hints or add filters. If it returns
+// an error the query isn't executed and the error is returned. Passing nil removes it.
+//
+// It's global, it's meant to be set once at startup.
+func OnQuery(fn func(ctx context.Context, query string, args []interface{}) (string, []interface{}, error)) {
+	queryHookMu.Lock()
+	defer queryHookMu.Unlock()
+
+	queryHook = fn
+}
+
+// tagKey is the context key of the tag of the query being executed.
+type tagKey struct{}
+
+// QueryTag returns the tag set with the Tag option for the query executed with ctx, or an empty string.
+// It's meant to be used by the function set with OnQuery and by drivers or wrappers reading the context,
+// to attribute the queries to their callers in logs and metrics.
+func QueryTag(ctx context.Context) string {
+	tag, _ := ctx.Value(tagKey{}).(string)
+	return tag
+}
+
+// queryContext executes query on q after passing it through the hook set with OnQuery. The tag, if not
+// empty, is added to ctx.
+func queryContext(ctx context.Context, q Queryer, tag, query string, args []interface{}) (*sql.Rows, error) {
+	if tag != "" {
+		ctx = context.WithValue(ctx, tagKey{}, tag)
+	}
+
+	queryHookMu.RLock()
+	hook := queryHook
+	queryHookMu.RUnlock()
+
+	if hook != nil {
+		var err error
+		if query, args, err = hook(ctx, query, args); err != nil {
+			return nil, err
+		}
+	}
+	return q.QueryContext(ctx, query, args...)
+}
+
+// TraceComment returns a function for OnQuery that appends a sqlcommenter comment with the tags returned
+// by tags for the query's context, like /*traceparent='00-4bf9...-01'*/, so that the database's slow query
+// logs can be correlated with the application traces. tags typically returns the traceparent of the span
+// in the context:
+//
+//	sqan.OnQuery(sqan.TraceComment(func(ctx context.Context) map[string]string {
+//		carrier := propagation.MapCarrier{}
+//		otel.GetTextMapPropagator().Inject(ctx, carrier)
+//		return carrier
+//	}))
+//
+// The tag of the query set with the Tag option, if any, is included with the "tag" key. Queries that
+// already contain a comment are left unchanged, as sqlcommenter specifies.
+func TraceComment(tags func(ctx context.Context) map[string]string) func(ctx context.Context, query string, args []interface{}) (string, []interface{}, error) {
+	return func(ctx context.Context, query string, args []interface{}) (string, []interface{}, error) {
+		if strings.Contains(query, "/*") || strings.Contains(query, "--") {
+			return query, args, nil
+		}
+
+		t := tags(ctx)
+		if tag := QueryTag(ctx); tag != "" {
+			withTag := make(map[string]string, len(t)+1)
+			for k, v := range t {
+				withTag[k] = v
+			}
+			withTag["tag"] = tag
+			t = withTag
+		}
+		return appendComment(query, t), args, nil
+	}
+}
+
+// appendComment adds a comment with the tags sorted by key at the end of the query, before the final semicolon.
+func appendComment(query string, tags map[string]string) string {
+	if len(tags) == 0 {
+		return query
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(commentEscape(k))
+		sb.WriteString("='")
+		sb.WriteString(commentEscape(tags[k]))
+		sb.WriteByte('\'')
+	}
+	sb.WriteString("*/")
+
+	query = strings.TrimRightFunc(query, unicode.IsSpace)
+	if strings.HasSuffix(query, ";") {
+		return query[:len(query)-1] + " " + sb.String() + ";"
+	}
+	return query + " " + sb.String()
+}
+
+// commentEscape URL-encodes s, which escapes quotes as well.
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,119 @@
+package sqan
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOnQuery(t *testing.T) {
+	OnQuery(func(ctx context.Context, query string, args []interface{}) (string, []interface{}, error) {
+		return query + " WHERE letter = $1", append(args, "A"), nil
+	})
+	defer OnQuery(nil)
+
+	cursor, err := Query[Test](context.Background(), db, "SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cursor.Close()
+
+	var got []Test
+	for v, ok := cursor.Next(); ok; v, ok = cursor.Next() {
+		got = append(got, v)
+	}
+	if err := cursor.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := records[:1]
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		errHook := errors.New("query rejected")
+		OnQuery(func(ctx context.Context, query string, args []interface{}) (string, []interface{}, error) {
+			return "", nil, errHook
+		})
+
+		if _, err := Query[Test](context.Background(), db, "SELECT * FROM tests"); !errors.Is(err, errHook) {
+			t.Errorf("Expected the hook error, got %v", err)
+		}
+	})
+}
+
+func TestTraceComment(t *testing.T) {
+	hook := TraceComment(func(ctx context.Context) map[string]string {
+		return map[string]string{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"route":       "/users/{id}",
+		}
+	})
+
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{
+			query:    "SELECT * FROM tests",
+			expected: "SELECT * FROM tests /*route='%2Fusers%2F%7Bid%7D',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/",
+		},
+		{
+			query:    "SELECT * FROM tests; ",
+			expected: "SELECT * FROM tests /*route='%2Fusers%2F%7Bid%7D',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/;",
+		},
+		{
+			query:    "SELECT * FROM tests /* existing */",
+			expected: "SELECT * FROM tests /* existing */",
+		},
+	}
+
+	for _, tc := range cases {
+		got, _, err := hook(context.Background(), tc.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, got)
+		}
+	}
+
+	t.Run("Tag", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), tagKey{}, "tests.list")
+		got, _, err := hook(ctx, "SELECT 1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "SELECT 1 /*route='%2Fusers%2F%7Bid%7D',tag='tests.list',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/"
+		if got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+}
+
+func TestTag(t *testing.T) {
+	var tag string
+	OnQuery(func(ctx context.Context, query string, args []interface{}) (string, []interface{}, error) {
+		tag = QueryTag(ctx)
+		return query, args, nil
+	})
+	defer OnQuery(nil)
+
+	var stats Stats
+	ctx := WithOptions(context.Background(), Tag("tests.list"), CollectStats(&stats))
+	cursor, err := Query[Test](ctx, db, "SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor.Close()
+
+	if tag != "tests.list" {
+		t.Errorf("Expected the query tag to be %q, got %q", "tests.list", tag)
+	}
+	if stats.Tag != "tests.list" {
+		t.Errorf("Expected the stats tag to be %q, got %q", "tests.list", stats.Tag)
+	}
+}
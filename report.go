@@ -0,0 +1,145 @@
+package sqan
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ScanReport describes how the columns of a result were mapped to the destination fields.
+//
+// Printing it with the %+v verb gives a detailed description of every column and field.
+type ScanReport struct {
+	// Columns contains the result columns in order, with the fields they were scanned into
+	Columns []ColumnMapping
+	// Unset contains the paths of the mapped fields no column was scanned into,
+	// they keep the value they had before scanning
+	Unset []string
+}
+
+// ColumnMapping is a column and the path of the field it was scanned into, which is empty if
+// the column was skipped.
+type ColumnMapping struct {
+	Column string
+	Field  string
+}
+
+// Report populates r with the mapping of the columns to the destination struct fields.
+//
+// It's meant for debugging, to find out why a field wasn't populated.
+func Report(r *ScanReport) Option {
+	return func(o *options) {
+		o.report = r
+	}
+}
+
+// Format implements fmt.Formatter.
+func (r ScanReport) Format(f fmt.State, verb rune) {
+	if verb != 'v' || !f.Flag('+') {
+		columns := make([]string, len(r.Columns))
+		for i, c := range r.Columns {
+			field := c.Field
+			if field == "" {
+				field = "-"
+			}
+			columns[i] = c.Column + ":" + field
+		}
+		fmt.Fprintf(f, "{columns: [%s] unset: [%s]}", strings.Join(columns, " "), strings.Join(r.Unset, " "))
+		return
+	}
+
+	for _, c := range r.Columns {
+		if c.Field == "" {
+			fmt.Fprintf(f, "column %q: skipped\n", c.Column)
+			continue
+		}
+		fmt.Fprintf(f, "column %q: scanned into %s\n", c.Column, c.Field)
+	}
+	for _, field := range r.Unset {
+		fmt.Fprintf(f, "field %s: no column, value unchanged\n", field)
+	}
+}
+
+// fillReport populates the report with the columns and the fields they are scanned into.
+func fillReport(r *ScanReport, columns []string, columnFields []*field, mapping map[string]*field) {
+	*r = ScanReport{Columns: make([]ColumnMapping, len(columns))}
+	for i, c := range columns {
+		r.Columns[i].Column = c
+		if f := columnFields[i]; f != nil {
+			r.Columns[i].Field = f.path
+		}
+	}
+
+	for _, f := range unsetFields(columnFields, mapping) {
+		r.Unset = append(r.Unset, f.path)
+	}
+}
+
+// unsetFields returns the fields that no column is scanned into, in the order they are declared.
+func unsetFields(columnFields []*field, mapping map[string]*field) []*field {
+	var unset []*field
+	for _, f := range mapping {
+		if isComposite(f.typ) || covered(f, columnFields) {
+			continue
+		}
+		unset = append(unset, f)
+	}
+	sort.Slice(unset, func(i, j int) bool {
+		return lessIndex(unset[i].index, unset[j].index)
+	})
+	return unset
+}
+
+// covered returns whether f or one of its parents is scanned.
+func covered(f *field, columnFields []*field) bool {
+	for _, cf := range columnFields {
+		if cf != nil && hasIndexPrefix(f.index, cf.index) {
+			return true
+		}
+	}
+	return false
+}
+
+// DumpMapping writes a table with the columns T is mapped to, the path and type of their fields
+// and their tag options, in the order the fields are declared.
+func DumpMapping[T any](w io.Writer) error {
+	t := baseType(reflect.TypeOf((*T)(nil)).Elem())
+	if t.Kind() != reflect.Struct {
+		return codeErrorf(CodeInvalidDest, "type must be a struct")
+	}
+
+	mapping, err := typeMapping(t, naming{})
+	if err != nil {
+		return err
+	}
+
+	columns := make([]string, 0, len(mapping))
+	for c := range mapping {
+		columns = append(columns, c)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return lessIndex(mapping[columns[i]].index, mapping[columns[j]].index)
+	})
+
+	var buf strings.Builder
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLUMN\tFIELD\tTYPE\tOPTIONS")
+	for _, c := range columns {
+		f := mapping[c]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c, f.path, f.typ, f.opts)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Remove the padding left by empty options
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \n")
+	}
+	_, err = io.WriteString(w, strings.Join(lines, "\n"))
+	return err
+}
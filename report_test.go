@@ -0,0 +1,68 @@
+package sqan
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	expected := ScanReport{
+		Columns: []ColumnMapping{
+			{Column: "letter", Field: "Letter"},
+			{Column: "?column?", Field: ""},
+			{Column: "exported", Field: "Sub.Exported"},
+		},
+		Unset: []string{"Weight", "Lowercase"},
+	}
+	rows, err := db.Query("SELECT letter, 1, exported FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		got    []Test
+		report ScanReport
+	)
+	if err := Rows(&got, rows, NormalizeColumns(CleanColumn), Report(&report)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, report) {
+		t.Errorf("Expected %v, got %v", expected, report)
+	}
+
+	expectedText := `column "letter": scanned into Letter
+column "?column?": skipped
+column "exported": scanned into Sub.Exported
+field Weight: no column, value unchanged
+field Lowercase: no column, value unchanged
+`
+	if text := fmt.Sprintf("%+v", report); text != expectedText {
+		t.Errorf("Expected %q, got %q", expectedText, text)
+	}
+}
+
+func TestDumpMapping(t *testing.T) {
+	type dump struct {
+		ID   int    `db:"id,ord=1"`
+		Name string `db:"name,optional"`
+		Sub  *Sub
+	}
+	expected := `COLUMN    FIELD         TYPE       OPTIONS
+id        ID            int        ord=1
+name      Name          string     optional
+sub       Sub           *sqan.Sub
+exported  Sub.Exported  bool
+`
+
+	var buf strings.Builder
+	if err := DumpMapping[dump](&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
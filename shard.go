@@ -0,0 +1,74 @@
+package sqan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ShardError is an error that occurred querying or scanning the result of a shard.
+type ShardError struct {
+	Err error
+	// Shard is the position of the shard in the list passed to SelectSharded
+	Shard int
+}
+
+func (e *ShardError) Error() string {
+	return fmt.Sprintf(translate("shard %d: %v"), e.Shard, e.Err)
+}
+
+func (e *ShardError) Unwrap() error {
+	return e.Err
+}
+
+// SelectSharded executes query on all the shards concurrently and appends the rows of their results to dest,
+// a pointer to a slice, in the order of the shards.
+//
+// If any shard fails dest is left unmodified and the errors of all the shards that failed are returned
+// as *ShardError, joined with errors.Join.
+func SelectSharded(ctx context.Context, shards []Queryer, dest interface{}, query string, args ...interface{}) error {
+	value, err := destValue(dest)
+	if err != nil {
+		return err
+	}
+	if value.Kind() != reflect.Slice {
+		return codeErrorf(CodeInvalidDest, "dest must be a pointer to a slice, got %s", reflect.PtrTo(value.Type()))
+	}
+
+	tag := contextTag(ctx)
+	var (
+		wg      sync.WaitGroup
+		results = make([]reflect.Value, len(shards))
+		errs    = make([]error, len(shards))
+	)
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard Queryer) {
+			defer wg.Done()
+
+			rows, err := queryContext(ctx, shard, tag, query, args)
+			if err != nil {
+				errs[i] = &ShardError{Shard: i, Err: err}
+				return
+			}
+			result := reflect.New(value.Type())
+			if err := Rows(result.Interface(), rows, contextOptions(ctx, nil)...); err != nil {
+				errs[i] = &ShardError{Shard: i, Err: err}
+				return
+			}
+			results[i] = result.Elem()
+		}(i, shard)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		value.Set(reflect.AppendSlice(value, result))
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package sqan
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectSharded(t *testing.T) {
+	var got []Test
+	shards := []Queryer{db, db}
+	if err := SelectSharded(context.Background(), shards, &got, "SELECT * FROM tests WHERE weight > $1", 50); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Test{records[0], records[2], records[0], records[2]}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestSelectShardedError(t *testing.T) {
+	var got []Test
+	shards := []Queryer{db, db}
+	err := SelectSharded(context.Background(), shards, &got, "SELECT * FROM unknown")
+
+	var shardErr *ShardError
+	if !errors.As(err, &shardErr) {
+		t.Fatalf("Expected a shard error, got %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected dest to be unmodified, got %v", got)
+	}
+}
@@ -0,0 +1,124 @@
+package sqan
+
+import (
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// MaxBytesError is returned when the rows scanned exceed the memory limit set with MaxBytes.
+type MaxBytesError struct {
+	// Limit is the maximum number of bytes
+	Limit int64
+	// Rows is the number of rows scanned when the limit was exceeded
+	Rows int
+}
+
+func (e *MaxBytesError) Error() string {
+	return fmt.Sprintf(translate("the rows scanned exceed the limit of %d bytes after %d rows"), e.Limit, e.Rows)
+}
+
+// MaxBytes aborts Rows and the functions returning slices or maps, like All, CollectMap, Poly, Pivot and
+// Changes, with a *MaxBytesError once the memory used by the rows scanned exceeds n bytes, to protect
+// servers from queries returning huge results.
+//
+// The memory used is estimated from the values scanned: the size of the destination type plus the length
+// of the strings, slices and maps and the size of the values pointed to by its exported fields.
+func MaxBytes(n int64) Option {
+	return func(o *options) {
+		o.maxBytes = n
+	}
+}
+
+// countBytes adds the size of v to used and returns a *MaxBytesError if the limit is exceeded.
+func (o *options) countBytes(used *int64, v reflect.Value, rows int) error {
+	if o.maxBytes <= 0 {
+		return nil
+	}
+	if *used += sizeOf(v); *used > o.maxBytes {
+		return &MaxBytesError{Limit: o.maxBytes, Rows: rows}
+	}
+	return nil
+}
+
+// sizeOf estimates the number of bytes used by v.
+func sizeOf(v reflect.Value) int64 {
+	return int64(v.Type().Size()) + indirectSize(v)
+}
+
+// indirectSize estimates the number of bytes referenced by v that aren't part of its type's size.
+//
+// Unexported fields are skipped, they aren't scanned and may point to shared values, like time.Time's location.
+func indirectSize(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return sizeOf(v.Elem())
+	case reflect.Slice:
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += indirectSize(v.Index(i))
+		}
+		return size
+	case reflect.Map:
+		var size int64
+		iter := v.MapRange()
+		for iter.Next() {
+			size += sizeOf(iter.Key()) + sizeOf(iter.Value())
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				size += indirectSize(v.Field(i))
+			}
+		}
+		return size
+	}
+	return 0
+}
+
+// SizeError is returned when a value is longer than the size set in the "size" option of its field's tag.
+type SizeError struct {
+	Column string
+	// Field is the path of the field from the root struct
+	Field string
+	// Size is the maximum length of the field
+	Size int
+	// Length is the length of the value, in characters for strings and in bytes for byte slices
+	Length int
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf(translate("column %q has a value of length %d but field %s allows up to %d"), e.Column, e.Length, e.Field, e.Size)
+}
+
+// checkSizes returns a *SizeError if the value of a field scanned is longer than its size.
+func checkSizes(v reflect.Value, columns []string, columnFields []*field) error {
+	for i, f := range columnFields {
+		if f == nil || f.size == 0 {
+			continue
+		}
+
+		value := reflect.Indirect(v.FieldByIndex(f.index))
+		length := 0
+		switch {
+		case value.Kind() == reflect.String:
+			length = utf8.RuneCountInString(value.String())
+		case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+			length = value.Len()
+		default:
+			continue
+		}
+
+		if length > f.size {
+			return &SizeError{Column: columns[i], Field: f.path, Size: f.size, Length: length}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package sqan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMaxBytes(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	err = Rows(&got, rows, MaxBytes(1))
+
+	var maxErr *MaxBytesError
+	if !errors.As(err, &maxErr) {
+		t.Fatalf("Expected a *MaxBytesError, got %v", err)
+	}
+	if maxErr.Rows != 1 {
+		t.Errorf("Expected the limit to be exceeded after 1 row, got %d", maxErr.Rows)
+	}
+
+	t.Run("Poly", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, 'test' AS kind FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Poly(rows, "kind", map[string]func() interface{}{
+			"test": func() interface{} { return &Test{} },
+		}, MaxBytes(1))
+		if !errors.As(err, &maxErr) {
+			t.Fatalf("Expected a *MaxBytesError, got %v", err)
+		}
+	})
+
+	t.Run("Pivot", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, lower_case, weight FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Pivot[string, bool, int](rows, "letter", "lower_case", "weight", MaxBytes(1))
+		if !errors.As(err, &maxErr) {
+			t.Fatalf("Expected a *MaxBytesError, got %v", err)
+		}
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		rows, err := db.Query("SELECT 'c' AS op, NULL AS old_letter, 'A' AS new_letter")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Changes[Test](rows, MaxBytes(1))
+		if !errors.As(err, &maxErr) {
+			t.Fatalf("Expected a *MaxBytesError, got %v", err)
+		}
+	})
+
+	t.Run("CollectMap", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = CollectMap(rows, func(v Test) string { return v.Letter }, MaxBytes(1))
+		if !errors.As(err, &maxErr) {
+			t.Fatalf("Expected a *MaxBytesError, got %v", err)
+		}
+	})
+}
+
+func TestSizeOf(t *testing.T) {
+	type sized struct {
+		Name  string
+		Data  []byte
+		Count *int
+	}
+	count := 1
+	v := sized{Name: "abc", Data: make([]byte, 2, 10), Count: &count}
+
+	// The struct, the string, the capacity of the slice and the int pointed to
+	expected := int64(reflect.TypeOf(v).Size()) + 3 + 10 + int64(reflect.TypeOf(count).Size())
+	if got := sizeOf(reflect.ValueOf(v)); got != expected {
+		t.Errorf("Expected %d, got %d", expected, got)
+	}
+}
+
+func TestSizeTag(t *testing.T) {
+	type sized struct {
+		Letter string  `db:"letter,size=1"`
+		Name   *string `db:"name,size=3"`
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, 'abc' AS name FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []sized
+		if err := Rows(&got, rows); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Too long", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, 'abcd' AS name FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got sized
+		err = Row(&got, rows)
+
+		var sizeErr *SizeError
+		if !errors.As(err, &sizeErr) {
+			t.Fatalf("Expected a *SizeError, got %v", err)
+		}
+		if sizeErr.Field != "Name" || sizeErr.Length != 4 {
+			t.Errorf("Expected field Name with length 4, got %s with length %d", sizeErr.Field, sizeErr.Length)
+		}
+	})
+
+	t.Run("Poly", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, 'abcd' AS name, 'sized' AS kind FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Poly(rows, "kind", map[string]func() interface{}{
+			"sized": func() interface{} { return &sized{} },
+		})
+		var sizeErr *SizeError
+		if !errors.As(err, &sizeErr) {
+			t.Fatalf("Expected a *SizeError, got %v", err)
+		}
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		rows, err := db.Query("SELECT 'u' AS op, 'A' AS old_letter, 'abc' AS old_name, 'B' AS new_letter, 'abcd' AS new_name")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = Changes[sized](rows)
+		var sizeErr *SizeError
+		if !errors.As(err, &sizeErr) {
+			t.Fatalf("Expected a *SizeError, got %v", err)
+		}
+		if sizeErr.Column != "new_name" {
+			t.Errorf("Expected column new_name, got %s", sizeErr.Column)
+		}
+	})
+
+	t.Run("KeyValues", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM (VALUES ('letter', 'A'), ('name', 'abcd')) AS settings(key, value)")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got sized
+		err = KeyValues(&got, rows)
+		var sizeErr *SizeError
+		if !errors.As(err, &sizeErr) {
+			t.Fatalf("Expected a *SizeError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package sqan
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+)
+
+// SparseJoins leaves the pointers to nested structs nil when all the columns scanned into their fields
+// are NULL, like the ones of a LEFT JOIN without a match, instead of allocating a zero value. It scans
+// each row twice to find the NULL values first.
+//
+//	type Order struct {
+//		ID       int
+//		Shipment *Shipment `db:",prefix=shipment_"`
+//	}
+func SparseJoins() Option {
+	return func(o *options) {
+		o.sparse = true
+	}
+}
+
+// sparsePointer is a pointer to a nested struct and the columns scanned into its fields.
+type sparsePointer struct {
+	index   []int
+	columns []int
+}
+
+// sparsePointers returns the pointers to nested structs of t that the columns are scanned into, the
+// shallowest first.
+func sparsePointers(t reflect.Type, columnFields []*field) []sparsePointer {
+	var pointers []sparsePointer
+	for i, f := range columnFields {
+		if f == nil {
+			continue
+		}
+
+		typ := t
+		for depth, x := range f.index[:len(f.index)-1] {
+			ft := typ.Field(x).Type
+			if ft.Kind() == reflect.Ptr {
+				pointers = addSparseColumn(pointers, f.index[:depth+1:depth+1], i)
+				ft = ft.Elem()
+			}
+			typ = ft
+		}
+	}
+
+	sort.SliceStable(pointers, func(i, j int) bool {
+		return len(pointers[i].index) < len(pointers[j].index)
+	})
+	return pointers
+}
+
+// addSparseColumn adds the column to the pointer with the index, which is added if it's not in pointers.
+func addSparseColumn(pointers []sparsePointer, index []int, column int) []sparsePointer {
+	for i, p := range pointers {
+		if reflect.DeepEqual(p.index, index) {
+			pointers[i].columns = append(pointers[i].columns, column)
+			return pointers
+		}
+	}
+	return append(pointers, sparsePointer{index: index, columns: []int{column}})
+}
+
+// sparseColumns scans the row to find the pointers of v whose columns are all NULL, sets them to nil and
+// returns columnFields without those columns, so that they are discarded instead of allocating the
+// pointers.
+func (o *options) sparseColumns(rows *sql.Rows, v reflect.Value, columnFields []*field, pointers []sparsePointer) ([]*field, error) {
+	if !o.sparse || len(pointers) == 0 {
+		return columnFields, nil
+	}
+
+	probes := make([]nullProbe, len(columnFields))
+	probeFields := make([]interface{}, len(columnFields))
+	for i := range probes {
+		probeFields[i] = &probes[i]
+	}
+	if err := rows.Scan(probeFields...); err != nil {
+		return nil, err
+	}
+
+	sparse, copied := columnFields, false
+	for _, p := range pointers {
+		if !allNull(probes, p.columns) {
+			continue
+		}
+		// Pointers nested in one that was set to nil are skipped
+		if ptr, ok := fieldByIndex(v, p.index); ok {
+			ptr.Set(reflect.Zero(ptr.Type()))
+		}
+		if !copied {
+			sparse, copied = append([]*field(nil), columnFields...), true
+		}
+		for _, c := range p.columns {
+			sparse[c] = nil
+		}
+	}
+	return sparse, nil
+}
+
+// allNull returns whether the probes of the columns are all NULL.
+func allNull(probes []nullProbe, columns []int) bool {
+	for _, c := range columns {
+		if !probes[c].null {
+			return false
+		}
+	}
+	return true
+}
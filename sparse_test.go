@@ -0,0 +1,64 @@
+package sqan
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Detail struct {
+	Weight   int
+	Exported bool
+	Extra    *Extra `db:"extra,prefix"`
+}
+
+type Extra struct {
+	Lowercase bool `db:"lower_case"`
+}
+
+func TestSparseJoins(t *testing.T) {
+	type joined struct {
+		Letter string
+		Detail *Detail `db:"detail,prefix"`
+	}
+	const query = `SELECT letter,
+		NULLIF(weight, 0) AS detail_weight,
+		CASE WHEN weight > 0 THEN exported END AS detail_exported,
+		CASE WHEN weight > 100 THEN lower_case END AS detail_extra_lower_case
+		FROM tests ORDER BY weight`
+
+	expected := []joined{
+		{Letter: "b"},
+		{Letter: "A", Detail: &Detail{Weight: 100, Exported: true}},
+		{Letter: "C", Detail: &Detail{Weight: 200, Exported: true, Extra: &Extra{Lowercase: false}}},
+	}
+
+	t.Run("Rows", func(t *testing.T) {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := All[joined](rows, SparseJoins())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Row", func(t *testing.T) {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := joined{Detail: &Detail{Weight: 1}}
+		if err := Row(&got, rows, SparseJoins()); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected[0], got) {
+			t.Errorf("Expected %v, got %v", expected[0], got)
+		}
+	})
+}
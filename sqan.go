@@ -3,40 +3,68 @@ package sqan
 
 import (
 	"database/sql"
-	"errors"
-	"fmt"
 	"reflect"
-	"strings"
+	"sort"
+	"strconv"
 	"sync"
 )
 
 var (
-	// [dest type]: [field name]: field indices
-	mappingCache      = make(map[reflect.Type]map[string][]int)
+	// ErrNoColumns is returned in strict mode when the result has no columns.
+	ErrNoColumns error = message("the result has no columns")
+	// ErrTooManyRows is returned when a single row was expected and the result has more.
+	ErrTooManyRows error = message("the result has more than one row")
+)
+
+var (
+	// [dest type and tag]: [column name]: field
+	mappingCache      = make(map[mappingKey]typeFields)
 	mu                sync.Mutex
 	_scannerInterface = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
 )
 
 // Row takes a struct of any type and scans a row on it.
-func Row(dest interface{}, rows *sql.Rows) error {
+//
+// If the result has no columns dest is left untouched and nil is returned, or ErrNoColumns in strict mode.
+//
+// dest may also be a pointer to a struct pointer, which is allocated if it's nil and there is a row to scan,
+// or a map[string]json.RawMessage, which receives the values of the columns encoded as JSON.
+func Row(dest interface{}, rows *sql.Rows, opts ...Option) error {
 	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
+
+	return scanRow(dest, rows, o, true)
+}
 
+// ScanCurrent scans the current row into dest like Row does, but it doesn't advance nor close rows.
+//
+// It assumes rows.Next() was already called, so it can be combined with manual iteration, for example
+// to branch on the value of a column before scanning the row.
+func ScanCurrent(dest interface{}, rows *sql.Rows, opts ...Option) error {
+	o := newOptions(opts)
+	defer o.done()
+
+	return scanRow(dest, rows, o, false)
+}
+
+// scanRow scans a row into dest, advancing rows first if advance is true.
+func scanRow(dest interface{}, rows *sql.Rows, o *options, advance bool) error {
 	value, err := destValue(dest)
 	if err != nil {
 		return err
 	}
+	if err := checkPointerLevels("dest", value.Type()); err != nil {
+		return err
+	}
 	bType := baseType(value.Type())
 	scannable := isScannable(bType)
 
-	if value.Kind() != reflect.Struct && !scannable {
-		return errors.New("dest type must be struct or implement the scanner interface")
+	if bType.Kind() != reflect.Struct && !scannable {
+		return codeErrorf(CodeInvalidDest, "dest type must be struct or implement the scanner interface")
 	}
-
-	for !rows.Next() {
-		if err := rows.Err(); err != nil {
-			return err
-		}
-		return sql.ErrNoRows
+	if err := checkInterface(bType); err != nil {
+		return err
 	}
 
 	columns, err := rows.Columns()
@@ -44,112 +72,248 @@ func Row(dest interface{}, rows *sql.Rows) error {
 		return err
 	}
 	if len(columns) == 0 {
+		return o.noColumns()
+	}
+
+	if advance && !o.next(rows) {
+		if err := o.err(rows); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	if value.Type() == _rawMessageMapType {
+		r, err := newJSONRow(rows, columns)
+		if err != nil {
+			return err
+		}
+		m, err := r.scan(rows)
+		if err := hookScanRow(err); err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(m))
 		return nil
 	}
 
 	if scannable {
 		if len(columns) > 1 {
-			return errors.New("scannable dest type with more than 1 column")
+			if bType.Kind() == reflect.Interface {
+				return codeErrorf(CodeInvalidDest, "interface dest can hold a single column only, use a struct to scan %d columns", len(columns))
+			}
+			return codeErrorf(CodeInvalidDest, "scannable dest type with more than 1 column")
 		}
-		return rows.Scan(dest)
+		return hookScanRow(rows.Scan(dest))
 	}
 
-	indices, err := columnsIndices(bType, columns)
+	columnFields, err := columnsFields(bType, rows, columns, o)
 	if err != nil {
 		return err
 	}
 
+	if value.Kind() == reflect.Ptr {
+		// dest is a pointer to a struct pointer, allocate it only once we know there is a row
+		if value.IsNil() {
+			value.Set(reflect.New(bType))
+		}
+		value = value.Elem()
+	}
+
+	if err := o.trace(rows, columns, columnFields); err != nil {
+		return hookScanRow(err)
+	}
+
+	decode := decodeTypes(rows, columnFields)
+	if o.sparse {
+		if columnFields, err = o.sparseColumns(rows, value, columnFields, sparsePointers(bType, columnFields)); err != nil {
+			return hookScanRow(err)
+		}
+	}
+
 	fields := make([]interface{}, len(columns))
-	for i, index := range indices {
-		allocNilPointers(value, index)
-		fields[i] = value.FieldByIndex(index).Addr().Interface()
+	fieldsAddrs(fields, value, columnFields, decode)
+	if err := o.handleNulls(rows, fields, value, columns, columnFields); err != nil {
+		return hookScanRow(err)
 	}
 
-	return rows.Scan(fields...)
+	if err := rows.Scan(fields...); err != nil {
+		return hookScanRow(err)
+	}
+	return hookScanRow(validate(value, columns, columnFields, o))
 }
 
 // Rows takes a slice of any type and scans the sql rows with it.
-func Rows(dest interface{}, rows *sql.Rows) error {
+//
+// The rows are appended to the elements dest already has, unless the ResetSlice option is used. If the
+// result has no columns dest is left untouched and nil is returned, or ErrNoColumns in strict mode.
+//
+// Slices of map[string]json.RawMessage receive the values of the columns encoded as JSON.
+func Rows(dest interface{}, rows *sql.Rows, opts ...Option) error {
 	defer rows.Close()
+	o := newOptions(opts)
+	defer o.done()
 
 	value, err := destValue(dest)
 	if err != nil {
 		return err
 	}
 
-	bType := baseType(value.Type())
-	if bType.Kind() != reflect.Slice {
-		return errors.New("dest must be a slice")
+	if value.Kind() != reflect.Slice {
+		return codeErrorf(CodeInvalidDest, "dest must be a pointer to a slice, got %s", reflect.PtrTo(value.Type()))
 	}
 
-	elem := bType.Elem()
-	baseElem := baseType(elem)
-	isScannable := isScannable(baseElem)
-	if baseElem.Kind() != reflect.Struct && !isScannable {
-		return errors.New("slice element must be a struct or a scannable type")
+	s, err := newRowScanner("slice element", value.Type().Elem(), rows, o)
+	if err != nil || s == nil {
+		return err
+	}
+
+	if o.resetSlice {
+		value.SetLen(0)
+	}
+
+	start := value.Len()
+	var used int64
+	for i := 0; o.next(rows); i++ {
+		slot := o.slot(i)
+		if slot == -1 {
+			continue
+		}
+		if i == 0 {
+			value.Grow(o.capacity())
+		}
+
+		v, err := s.scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := o.countBytes(&used, v, i+1); err != nil {
+			return err
+		}
+		if start+slot < value.Len() {
+			value.Index(start + slot).Set(v)
+		} else {
+			value.Set(reflect.Append(value, v))
+		}
+	}
+
+	return o.err(rows)
+}
+
+// rowScanner scans rows into new values of a type.
+type rowScanner struct {
+	typ reflect.Type
+	// base is typ without the pointer
+	base         reflect.Type
+	json         *jsonRow
+	columns      []string
+	columnFields []*field
+	// decode contains the types the columns scanned into interface{} fields are decoded into
+	decode []reflect.Type
+	// pointers are the pointers to nested structs left nil with SparseJoins
+	pointers  []sparsePointer
+	fields    []interface{}
+	o         *options
+	scannable bool
+}
+
+// newRowScanner validates that the values of type t, named name in the errors, can hold the rows.
+//
+// It returns a nil scanner if the result has no columns and that isn't an error.
+func newRowScanner(name string, t reflect.Type, rows *sql.Rows, o *options) (*rowScanner, error) {
+	if err := checkPointerLevels(name, t); err != nil {
+		return nil, err
+	}
+	s := &rowScanner{typ: t, base: baseType(t), o: o}
+	s.scannable = isScannable(s.base)
+	if s.base.Kind() != reflect.Struct && !s.scannable {
+		return nil, codeErrorf(CodeInvalidDest, "%s must be a struct or a scannable type", name)
+	}
+	if err := checkInterface(s.base); err != nil {
+		return nil, err
 	}
 
 	columns, err := rows.Columns()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(columns) == 0 {
-		return nil
+		return nil, o.noColumns()
 	}
 
-	isPtr := elem.Kind() == reflect.Ptr
-
-	if isScannable {
-		if len(columns) > 1 {
-			return errors.New("scannable dest slice elements with more than 1 column")
+	if t == _rawMessageMapType {
+		if s.json, err = newJSONRow(rows, columns); err != nil {
+			return nil, err
 		}
+		return s, nil
+	}
 
-		var vPtr reflect.Value // Reuse
-		for rows.Next() {
-			vPtr = reflect.New(baseElem)
-			if err := rows.Scan(vPtr.Interface()); err != nil {
-				return err
-			}
-
-			if !isPtr {
-				vPtr = reflect.Indirect(vPtr)
+	if s.scannable {
+		if len(columns) > 1 {
+			if s.base.Kind() == reflect.Interface {
+				return nil, codeErrorf(CodeInvalidDest, "interface %s can hold a single column only, use a struct to scan %d columns", name, len(columns))
 			}
-			value.Set(reflect.Append(value, vPtr))
+			return nil, codeErrorf(CodeInvalidDest, "scannable %s with more than 1 column", name)
 		}
-
-		return rows.Err()
+		return s, nil
 	}
 
-	indices, err := columnsIndices(baseElem, columns)
+	s.columnFields, err = columnsFields(s.base, rows, columns, o)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	s.columns = columns
+	s.decode = decodeTypes(rows, s.columnFields)
+	if o.sparse {
+		s.pointers = sparsePointers(s.base, s.columnFields)
+	}
+	s.fields = make([]interface{}, len(columns))
+	return s, nil
+}
 
-	// Reuse variables
-	var vPtr, v reflect.Value
-	fields := make([]interface{}, len(columns))
-
-	for rows.Next() {
-		vPtr = reflect.New(baseElem)
-		v = reflect.Indirect(vPtr)
+// scan scans the current row into a new value, passing the error to the afterScanRow test hook.
+func (s *rowScanner) scan(rows *sql.Rows) (reflect.Value, error) {
+	v, err := s.scanValue(rows)
+	return v, hookScanRow(err)
+}
 
-		for i, index := range indices {
-			allocNilPointers(v, index)
-			fields[i] = v.FieldByIndex(index).Addr().Interface()
+// scanValue scans the current row into a new value.
+func (s *rowScanner) scanValue(rows *sql.Rows) (reflect.Value, error) {
+	if s.json != nil {
+		m, err := s.json.scan(rows)
+		if err != nil {
+			return reflect.Value{}, err
 		}
+		return reflect.ValueOf(m), nil
+	}
 
-		if err := rows.Scan(fields...); err != nil {
-			return err
+	vPtr := reflect.New(s.base)
+	if s.scannable {
+		if err := rows.Scan(vPtr.Interface()); err != nil {
+			return reflect.Value{}, err
 		}
-
-		if isPtr {
-			value.Set(reflect.Append(value, vPtr))
-		} else {
-			value.Set(reflect.Append(value, v))
+	} else {
+		if err := s.o.trace(rows, s.columns, s.columnFields); err != nil {
+			return reflect.Value{}, err
+		}
+		columnFields, err := s.o.sparseColumns(rows, vPtr.Elem(), s.columnFields, s.pointers)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		fieldsAddrs(s.fields, vPtr.Elem(), columnFields, s.decode)
+		if err := s.o.handleNulls(rows, s.fields, vPtr.Elem(), s.columns, columnFields); err != nil {
+			return reflect.Value{}, err
+		}
+		if err := rows.Scan(s.fields...); err != nil {
+			return reflect.Value{}, err
+		}
+		if err := validate(vPtr.Elem(), s.columns, columnFields, s.o); err != nil {
+			return reflect.Value{}, err
 		}
 	}
 
-	return rows.Err()
+	if s.typ.Kind() == reflect.Ptr {
+		return vPtr, nil
+	}
+	return vPtr.Elem(), nil
 }
 
 // allonNilPointers allocates fields that are nil pointers to be scanned later.
@@ -157,14 +321,72 @@ func allocNilPointers(v reflect.Value, index []int) {
 	if len(index) == 0 {
 		return
 	}
-	if field := v.Field(index[0]); field.Kind() == reflect.Ptr {
+	field := v.Field(index[0])
+	if field.Kind() == reflect.Ptr {
 		if field.IsNil() {
 			// Field is a nil pointer, allocate a new value
 			field.Set(reflect.New(field.Type().Elem()))
 		}
 		// Dereference the field pointer to repeat the process a level below
-		allocNilPointers(reflect.Indirect(field), index[1:])
+		field = field.Elem()
+	}
+	allocNilPointers(field, index[1:])
+}
+
+// fieldsAddrs sets the addresses of the fields of v that the columns are scanned into.
+//
+// Columns with a type in decode are decoded into it, see decodeTypes.
+func fieldsAddrs(fields []interface{}, v reflect.Value, columnFields []*field, decode []reflect.Type) {
+	for i, f := range columnFields {
+		if f == nil {
+			fields[i] = discard{}
+			continue
+		}
+		allocNilPointers(v, f.index)
+		if decode != nil && decode[i] != nil {
+			fields[i] = anyValue{field: v.FieldByIndex(f.index), typ: decode[i]}
+			continue
+		}
+		fields[i] = v.FieldByIndex(f.index).Addr().Interface()
+	}
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but returns false if a nil pointer is found
+// in the way instead of panicking.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
 	}
+	return v, true
+}
+
+// lessIndex reports whether the field with index a is declared before the one with index b.
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// hasIndexPrefix reports whether the field with index prefix contains the field with index.
+func hasIndexPrefix(index, prefix []int) bool {
+	if len(prefix) > len(index) {
+		return false
+	}
+	for i, x := range prefix {
+		if index[i] != x {
+			return false
+		}
+	}
+	return true
 }
 
 // baseType returns a type element's type.
@@ -175,40 +397,204 @@ func baseType(t reflect.Type) reflect.Type {
 	return t
 }
 
+// checkPointerLevels returns an error if t has more than one level of pointers, as only T and *T
+// destinations are supported.
+func checkPointerLevels(name string, t reflect.Type) error {
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Ptr {
+		return codeErrorf(CodeInvalidDest, "%s type %s has too many pointer levels, only T and *T are supported", name, t)
+	}
+	return nil
+}
+
 // destValue validates dest is a non-nil pointer and returns the value that it points to.
 func destValue(dest interface{}) (reflect.Value, error) {
 	vPtr := reflect.ValueOf(dest)
 	if vPtr.Kind() != reflect.Ptr {
-		return reflect.Value{}, errors.New("dest must be a pointer")
+		return reflect.Value{}, codeErrorf(CodeInvalidDest, "dest must be a pointer")
 	}
 	if vPtr.IsNil() {
-		return reflect.Value{}, errors.New("dest mustn't be nil")
+		return reflect.Value{}, codeErrorf(CodeNilDest, "dest mustn't be nil")
 	}
 	return reflect.Indirect(vPtr), nil
 }
 
-// columnsIndices maps each field with its index
-func columnsIndices(t reflect.Type, columns []string) ([][]int, error) {
-	mu.Lock()
-	mapping, ok := mappingCache[t]
-	if !ok {
-		mapping = make(map[string][]int)
-		mapFields(t, mapping, nil)
-		mappingCache[t] = mapping
+// structValue returns the struct held by v, dereferencing it if it's a pointer.
+func structValue(v interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return reflect.Value{}, codeErrorf(CodeNilDest, "value mustn't be nil")
 	}
-	mu.Unlock()
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, codeErrorf(CodeNilDest, "value mustn't be nil")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, codeErrorf(CodeInvalidDest, "value must be a struct, got %s", value.Type())
+	}
+	return value, nil
+}
+
+// columnsFields returns the field each column is scanned into, columns that must be skipped have a nil field.
+func columnsFields(t reflect.Type, rows *sql.Rows, columns []string, o *options) ([]*field, error) {
+	mapping, ambiguous := o.mapping, o.ambiguous
+	if mapping == nil {
+		var err error
+		if mapping, ambiguous, err = mapType(t, o.naming); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make([]*field, 0, len(columns))
+	// The occurrences are counted by field, columns with different names, like "ID" and "id" with
+	// CaseInsensitive, may be scanned into the same one
+	var occurrences map[*field]int
+	if o.duplicates != DuplicateAll {
+		occurrences = make(map[*field]int, len(columns))
+	}
+	for i, c := range columns {
+		if o.normalize != nil {
+			if c = o.normalize(c); c == "" {
+				fields = append(fields, nil)
+				continue
+			}
+		}
 
-	indices := make([][]int, 0, len(columns))
-	for _, c := range columns {
-		index, ok := mapping[c]
+		f, ok := o.lookup(mapping, c)
+		if ok && occurrences != nil {
+			occurrences[f]++
+			if n := occurrences[f]; n > 1 {
+				switch o.duplicates {
+				case DuplicateError:
+					return nil, codeErrorf(CodeDuplicateColumn, "column %q appears more than once", c)
+				case DuplicateFirst:
+					fields = append(fields, nil)
+					continue
+				case DuplicatePositional:
+					c += "_" + strconv.Itoa(n)
+					f, ok = o.lookup(mapping, c)
+				}
+			}
+		}
 		if !ok {
-			return nil, fmt.Errorf("couldn't find a field for column %q", c)
+			if err := ambiguous[c]; err != nil {
+				return nil, err
+			}
+			if o.allowUnknown {
+				fields = append(fields, nil)
+				continue
+			}
+			return nil, codeErrorf(CodeUnmappedColumn, "couldn't find a field for column %q", c)
+		}
+		if o.checkOrder && f.ord != 0 && f.ord != i+1 {
+			return nil, codeErrorf(CodeColumnOrder, "column %q is in position %d but field %s expects it in position %d", c, i+1, f.path, f.ord)
+		}
+
+		fields = append(fields, f)
+	}
+
+	if o.strict {
+		if err := checkColumns(rows, columns, fields, mapping); err != nil {
+			return nil, err
+		}
+	}
+	if o.strict || o.warn != nil {
+		if err := checkNullable(rows, columns, fields, o); err != nil {
+			return nil, err
+		}
+	}
+	if o.nulls == NullRequire {
+		if err := requireNullable(columns, fields); err != nil {
+			return nil, err
 		}
+	}
 
-		indices = append(indices, index)
+	if o.report != nil {
+		fillReport(o.report, columns, fields, mapping)
 	}
 
-	return indices, nil
+	return fields, nil
+}
+
+// mappingKey identifies a cached mapping.
+type mappingKey struct {
+	t        reflect.Type
+	tag      string
+	maxDepth int
+	json     bool
+}
+
+// typeFields is the mapping of a type's fields to the columns.
+type typeFields struct {
+	mapping map[string]*field
+	// ambiguous contains the errors of the columns named after several fields that can't be told apart
+	ambiguous map[string]error
+}
+
+// typeMapping returns the columns mapping of t, it's computed only once and then cached.
+func typeMapping(t reflect.Type, n naming) (map[string]*field, error) {
+	mapping, _, err := mapType(t, n)
+	return mapping, err
+}
+
+// mapType returns the columns mapping of t and the errors of its ambiguous columns, they are computed
+// only once and then cached.
+//
+// Mappings using the name function of the options aren't cached as functions can't be compared,
+// SetNameMapper clears the cache instead.
+func mapType(t reflect.Type, n naming) (map[string]*field, map[string]error, error) {
+	if n.name != nil {
+		n = n.resolve()
+		mapping, ambiguous := make(map[string]*field), make(map[string]error)
+		if err := mapFields(t, mapping, ambiguous, n); err != nil {
+			return nil, nil, err
+		}
+		return mapping, ambiguous, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Resolve the defaults holding the cache lock, so that SetNameMapper can't replace the mapper until
+	// the mapping built with it is cached
+	n = n.resolve()
+	key := mappingKey{t: t, tag: n.tag, json: n.json, maxDepth: n.maxDepth}
+	tf, ok := mappingCache[key]
+	if !ok {
+		tf = typeFields{mapping: make(map[string]*field), ambiguous: make(map[string]error)}
+		if err := mapFields(t, tf.mapping, tf.ambiguous, n); err != nil {
+			return nil, nil, err
+		}
+		mappingCache[key] = tf
+	}
+
+	return tf.mapping, tf.ambiguous, nil
+}
+
+// checkInterface returns an error if t is an interface type that can't hold the values scanned.
+//
+// Only the empty interface is supported, it receives the value as returned by the driver.
+func checkInterface(t reflect.Type) error {
+	if t.Kind() == reflect.Interface && t.NumMethod() != 0 {
+		return codeErrorf(CodeInvalidDest, "%s is an interface with methods, use a struct, a scannable type or interface{} instead", t)
+	}
+	return nil
+}
+
+// discard is a scanner that ignores the values it receives.
+type discard struct{}
+
+func (discard) Scan(interface{}) error { return nil }
+
+// nullProbe is a scanner that records whether the value it receives is NULL.
+type nullProbe struct {
+	null bool
+}
+
+func (n *nullProbe) Scan(src interface{}) error {
+	n.null = src == nil
+	return nil
 }
 
 func isScannable(t reflect.Type) bool {
@@ -218,34 +604,150 @@ func isScannable(t reflect.Type) bool {
 	return false
 }
 
-// mapFields populates a map with fields and their indices. It maps a type recursively.
+// field is a struct field mapped to a column.
+type field struct {
+	typ  reflect.Type
+	opts tagOptions
+	// path contains the names of the fields from the root struct, separated by dots
+	path  string
+	index []int
+	// ord is the position the column is expected to be in (starting from 1), zero if not set
+	ord int
+	// size is the maximum length of the values, zero if not set
+	size int
+	// min and max are the bounds of numeric values, used if hasMin and hasMax are true
+	min, max       float64
+	hasMin, hasMax bool
+	nonEmpty       bool
+	// tagged is whether the column name is set in the field's tag
+	tagged bool
+}
+
+// mapFields populates a map with the fields of t and their indices.
 //
-// Unexported fields and struct slices are skipped.
-func mapFields(t reflect.Type, mapping map[string][]int, parentIndices []int) {
+// When several fields are named after the same column, it's mapped to the dominant one following the
+// rules of encoding/json: the shallowest field, and among those at the same depth, the only one with the
+// name in its tag. Otherwise the column is ambiguous, it isn't mapped and the error returned when it's
+// scanned is stored in ambiguous.
+func mapFields(t reflect.Type, mapping map[string]*field, ambiguous map[string]error, n naming) error {
+	candidates := make(map[string][]*field)
+	if err := collectFields(t, candidates, make(map[reflect.Type]bool), nil, "", "", n); err != nil {
+		return err
+	}
+
+	for name, fields := range candidates {
+		f, err := dominantField(name, fields)
+		if err != nil {
+			ambiguous[name] = err
+			continue
+		}
+		mapping[name] = f
+	}
+	return nil
+}
+
+// dominantField returns the field a column named after all the fields is mapped to.
+func dominantField(column string, fields []*field) (*field, error) {
+	if len(fields) == 1 {
+		return fields[0], nil
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+		if a.tagged != b.tagged {
+			return a.tagged
+		}
+		return lessIndex(a.index, b.index)
+	})
+
+	a, b := fields[0], fields[1]
+	if len(a.index) == len(b.index) && a.tagged == b.tagged {
+		return nil, codeErrorf(CodeAmbiguousColumn, "column %q is mapped to fields %s and %s, rename one of them in its tag or use a prefix",
+			column, a.path, b.path)
+	}
+	return a, nil
+}
+
+// collectFields populates a map with the fields named after each column and their indices. It maps a
+// type recursively, the names of the columns are prefixed with parentPrefix.
+//
+// Unexported fields, fields tagged with "-" and struct slices are skipped, the fields of types implementing
+// sql.Scanner aren't mapped. The fields of struct types that are already being mapped (ancestors), like
+// the Parent of a tree node, and those nested deeper than the maximum depth aren't mapped either.
+func collectFields(t reflect.Type, candidates map[string][]*field, ancestors map[reflect.Type]bool, parentIndex []int, parentPath, parentPrefix string, n naming) error {
+	ancestors[t] = true
+	defer delete(ancestors, t)
+
 	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if !field.IsExported() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(n.tag)
+		if !sf.IsExported() || tag == "-" {
 			continue
 		}
 
-		indices := append(parentIndices, field.Index...)
+		index := make([]int, len(parentIndex)+1)
+		copy(index, parentIndex)
+		index[len(parentIndex)] = i
+		path := sf.Name
+		if parentPath != "" {
+			path = parentPath + "." + sf.Name
+		}
 
-		bType := baseType(field.Type)
+		name, opts := parseTag(tag)
+		if name == "" && n.json {
+			if jsonName, _ := parseTag(sf.Tag.Get("json")); jsonName != "-" {
+				name = jsonName
+			}
+		}
+		tagged := name != ""
+		if name == "" {
+			name = n.column(sf.Name)
+		}
+
+		bType := baseType(sf.Type)
 		kind := bType.Kind()
-		if kind == reflect.Struct {
-			// if the field's base type is a struct, map it as well
-			mapFields(bType, mapping, indices)
+		prefix, hasPrefix := opts.Get("prefix")
+		if kind == reflect.Struct && !reflect.PtrTo(bType).Implements(_scannerInterface) {
+			// if the field's base type is a struct, map it as well, scanners receive the column as a whole
+			if hasPrefix && prefix == "" {
+				prefix = name + "_"
+			}
+			if !ancestors[bType] && (n.maxDepth <= 0 || len(index) < n.maxDepth) {
+				if err := collectFields(bType, candidates, ancestors, index, path, parentPrefix+prefix, n); err != nil {
+					return err
+				}
+			}
+		} else if hasPrefix {
+			return codeErrorf(CodeInvalidTag, "invalid prefix in field %s, only struct fields can have one", path)
 		} else if kind == reflect.Slice && bType.Elem().Kind() == reflect.Struct {
 			continue
 		}
+		name = parentPrefix + name
 
-		fieldName := ""
-		if tag := field.Tag.Get("db"); tag != "" {
-			fieldName = tag
-		} else {
-			fieldName = strings.ToLower(field.Name)
+		f := &field{typ: sf.Type, opts: opts, path: path, index: index, tagged: tagged}
+		if ord, ok := opts.Get("ord"); ok {
+			n, err := strconv.Atoi(ord)
+			if err != nil || n < 1 {
+				return codeErrorf(CodeInvalidTag, "invalid ord %q in field %s, it must be a positive integer", ord, path)
+			}
+			f.ord = n
+		}
+		if err := parseAssertions(f); err != nil {
+			return err
+		}
+		if size, ok := opts.Get("size"); ok {
+			n, err := strconv.Atoi(size)
+			if err != nil || n < 1 {
+				return codeErrorf(CodeInvalidTag, "invalid size %q in field %s, it must be a positive integer", size, path)
+			}
+			f.size = n
 		}
 
-		mapping[fieldName] = indices
+		candidates[name] = append(candidates[name], f)
 	}
+
+	return nil
 }
@@ -2,9 +2,12 @@ package sqan
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	_ "github.com/lib/pq"
@@ -102,6 +105,23 @@ func TestRows(t *testing.T) {
 			t.Errorf("Expected %v, got %v", records, got)
 		}
 	})
+
+	t.Run("Interface slice", func(t *testing.T) {
+		expected := []interface{}{"A", "b", "C"}
+		rows, err := db.Query("SELECT letter FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []interface{}
+		if err := Rows(&got, rows); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
 }
 
 func TestRowsErrors(t *testing.T) {
@@ -130,6 +150,14 @@ func TestRowsErrors(t *testing.T) {
 			desc: "Not a struct slice",
 			dest: &[]int{},
 		},
+		{
+			desc: "Interface with methods",
+			dest: &[]fmt.Stringer{},
+		},
+		{
+			desc: "Pointer to pointer elements",
+			dest: &[]**Test{},
+		},
 	}
 
 	for _, tc := range cases {
@@ -159,6 +187,24 @@ func TestRow(t *testing.T) {
 	}
 }
 
+func TestRowPointer(t *testing.T) {
+	target := records[0]
+	expected := &Test{Weight: target.Weight, Sub: target.Sub}
+	rows, err := db.Query("SELECT weight, exported FROM tests WHERE letter=$1", target.Letter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *Test
+	if err := Row(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
 func TestRowErrors(t *testing.T) {
 	rows, err := db.Query("SELECT 1 FROM tests")
 	if err != nil {
@@ -181,6 +227,10 @@ func TestRowErrors(t *testing.T) {
 			desc: "Not a struct",
 			dest: "text",
 		},
+		{
+			desc: "Too many pointer levels",
+			dest: new(**Test),
+		},
 	}
 
 	for _, tc := range cases {
@@ -191,3 +241,783 @@ func TestRowErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectStats(t *testing.T) {
+	rows, err := db.Query("SELECT letter, weight, lower_case, exported FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		got   []Test
+		stats Stats
+	)
+	if err := Rows(&got, rows, CollectStats(&stats)); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Rows != len(records) {
+		t.Errorf("Expected %d rows, got %d", len(records), stats.Rows)
+	}
+	if stats.Fetch <= 0 || stats.Decode < 0 {
+		t.Errorf("Expected positive durations, got %+v", stats)
+	}
+}
+
+func TestSample(t *testing.T) {
+	sample := func() []Test {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []Test
+		if err := Rows(&got, rows, Sample(2, 1)); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	got := sample()
+	if len(got) != 2 || reflect.DeepEqual(got[0], got[1]) {
+		t.Fatalf("Expected 2 different rows, got %v", got)
+	}
+	for _, g := range got {
+		found := false
+		for _, r := range records {
+			found = found || reflect.DeepEqual(g, r)
+		}
+		if !found {
+			t.Errorf("Unexpected row %v", g)
+		}
+	}
+
+	if again := sample(); !reflect.DeepEqual(got, again) {
+		t.Errorf("Expected the same sample with the same seed, got %v and %v", got, again)
+	}
+}
+
+func TestAllowUnknownColumns(t *testing.T) {
+	type letter struct {
+		Letter string
+	}
+	expected := []letter{{Letter: "A"}, {Letter: "b"}, {Letter: "C"}}
+
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []letter
+	if err := Rows(&got, rows, AllowUnknownColumns()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestTagName(t *testing.T) {
+	type tagged struct {
+		Char   string `sql:"letter"`
+		Weight int    `db:"letter"`
+	}
+	expected := []tagged{{Char: "A", Weight: 100}, {Char: "b", Weight: 0}, {Char: "C", Weight: 200}}
+
+	rows, err := db.Query("SELECT letter, weight FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []tagged
+	if err := Rows(&got, rows, TagName("sql")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestJSONTags(t *testing.T) {
+	type apiTest struct {
+		Char      string `json:"letter"`
+		Lowercase bool   `json:"lower_case,omitempty"`
+		Weight    int    `json:"-" db:"weight"`
+	}
+	expected := []apiTest{{Char: "A", Weight: 100}, {Char: "b", Lowercase: true}, {Char: "C", Weight: 200}}
+
+	rows, err := db.Query("SELECT letter, lower_case, weight FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []apiTest
+	if err := Rows(&got, rows, JSONTags()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []apiTest
+		if err := Rows(&got, rows); ErrorCode(err) != CodeUnmappedColumn {
+			t.Errorf("Expected an unmapped column error, got %v", err)
+		}
+	})
+}
+
+func TestSkipTag(t *testing.T) {
+	type secret struct {
+		Letter string
+		Weight int `db:"-"`
+		Sub    Sub `db:"-"`
+	}
+	expected := []secret{{Letter: "A"}, {Letter: "b"}, {Letter: "C"}}
+
+	rows, err := db.Query("SELECT letter, weight, exported FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []secret
+	if err := Rows(&got, rows, AllowUnknownColumns()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	t.Run("Column", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, weight FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []secret
+		if err := Rows(&got, rows); ErrorCode(err) != CodeUnmappedColumn {
+			t.Errorf("Expected an unmapped column error, got %v", err)
+		}
+	})
+}
+
+func TestPrefix(t *testing.T) {
+	type weighted struct {
+		Letter string
+		Weight int
+	}
+	type pair struct {
+		First  weighted `db:"a,prefix"`
+		Second weighted `db:",prefix=b_"`
+	}
+	expected := []pair{
+		{First: weighted{Letter: "A", Weight: 100}, Second: weighted{Letter: "A", Weight: 200}},
+		{First: weighted{Letter: "b", Weight: 0}, Second: weighted{Letter: "B", Weight: 0}},
+		{First: weighted{Letter: "C", Weight: 200}, Second: weighted{Letter: "C", Weight: 400}},
+	}
+
+	rows, err := db.Query(`SELECT letter AS a_letter, weight AS a_weight, upper(letter) AS b_letter,
+	weight * 2 AS b_weight FROM tests`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []pair
+	if err := Rows(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		type invalid struct {
+			Letter string `db:"letter,prefix"`
+		}
+		if _, err := NewMapper[invalid](); ErrorCode(err) != CodeInvalidTag {
+			t.Errorf("Expected an invalid tag error, got %v", err)
+		}
+	})
+}
+
+func TestSetTagName(t *testing.T) {
+	type tagged struct {
+		Char   string `sql:"letter"`
+		Weight int    `col:"weight"`
+	}
+	SetTagName("sql")
+	defer SetTagName("db")
+
+	rows, err := db.Query("SELECT letter, weight FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []tagged
+	if err := Rows(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Char != "A" || got[0].Weight != 100 {
+		t.Errorf("Expected the sql tag to be used, got %v", got[0])
+	}
+
+	t.Run("Mapper", func(t *testing.T) {
+		mapper, err := NewMapper[tagged](TagName("col"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := db.Query("SELECT letter AS char, weight FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := mapper.ScanRow(rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Char != "A" || got.Weight != 100 {
+			t.Errorf("Expected the col tag to be used, got %v", got)
+		}
+	})
+}
+
+func TestNameMapping(t *testing.T) {
+	type upper struct {
+		Letter string
+		Weight int
+	}
+	expected := []upper{{Letter: "A", Weight: 100}, {Letter: "b", Weight: 0}, {Letter: "C", Weight: 200}}
+
+	rows, err := db.Query(`SELECT letter AS "LETTER", weight AS "WEIGHT" FROM tests`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []upper
+	if err := Rows(&got, rows, NameMapping(strings.ToUpper)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestSetNameMapper(t *testing.T) {
+	type camel struct {
+		Letter    string
+		LowerCase bool
+	}
+	expected := []camel{{Letter: "A", LowerCase: false}, {Letter: "b", LowerCase: true}, {Letter: "C", LowerCase: false}}
+
+	// Cache the mapping computed with the default function
+	if _, err := typeMapping(reflect.TypeOf(camel{}), naming{}); err != nil {
+		t.Fatal(err)
+	}
+
+	SetNameMapper(SnakeCase)
+	defer SetNameMapper(nil)
+
+	rows, err := db.Query("SELECT letter, lower_case FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []camel
+	if err := Rows(&got, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	t.Run("Concurrent", func(t *testing.T) {
+		// Run with -race
+		typ := reflect.TypeOf(struct{ LowerCase bool }{})
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if i%2 == 0 {
+					SetNameMapper(nil)
+				} else {
+					SetNameMapper(SnakeCase)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				if _, err := typeMapping(typ, naming{}); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		SetNameMapper(SnakeCase)
+		mapping, err := typeMapping(typ, naming{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := mapping["lower_case"]; !ok {
+			t.Errorf("Expected LowerCase to be mapped to lower_case, got %v", mapping)
+		}
+	})
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	expected := []Test{
+		{Letter: "A", Weight: 100},
+		{Letter: "b", Weight: 0, Lowercase: true},
+		{Letter: "C", Weight: 200},
+	}
+	rows, err := db.Query(`SELECT letter AS "LETTER", weight AS "Weight", lower_case AS "Lower_Case" FROM tests`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	if err := Rows(&got, rows, CaseInsensitive()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	expected := []Test{
+		{Letter: "A", Weight: 100},
+		{Letter: "b", Weight: 0, Lowercase: true},
+		{Letter: "C", Weight: 200},
+	}
+	rows, err := db.Query("SELECT letter AS ltr, weight AS wgt, lower_case AS lc FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	aliases := map[string]string{"ltr": "Letter", "wgt": "Weight", "lc": "lower_case"}
+	if err := Rows(&got, rows, Aliases(aliases)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestSizeHint(t *testing.T) {
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	if err := Rows(&got, rows, SizeHint(10)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(records, got) {
+		t.Errorf("Expected %v, got %v", records, got)
+	}
+	if cap(got) < 10 {
+		t.Errorf("Expected a capacity of at least 10, got %d", cap(got))
+	}
+
+	t.Run("Limit", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := All[Test](rows, SizeHint(10), Limit(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(records[:2], got) {
+			t.Errorf("Expected %v, got %v", records[:2], got)
+		}
+		if cap(got) != 2 {
+			t.Errorf("Expected a capacity of 2, got %d", cap(got))
+		}
+	})
+}
+
+func TestResetSlice(t *testing.T) {
+	buf := make([]Test, 1, 10)
+	buf[0] = Test{Letter: "Z"}
+	backing := &buf[0]
+
+	rows, err := db.Query("SELECT * FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Rows(&buf, rows, ResetSlice()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records, buf) {
+		t.Errorf("Expected %v, got %v", records, buf)
+	}
+	if &buf[0] != backing {
+		t.Error("Expected the capacity of the slice to be reused")
+	}
+}
+
+func TestDuplicateColumns(t *testing.T) {
+	type pair struct {
+		Letter  string
+		Letter2 string `db:"letter_2"`
+	}
+	query := "SELECT letter, upper(letter) AS letter FROM tests WHERE letter = 'b'"
+
+	cases := []struct {
+		desc     string
+		policy   DuplicatePolicy
+		expected pair
+	}{
+		{
+			desc:     "All",
+			policy:   DuplicateAll,
+			expected: pair{Letter: "B"},
+		},
+		{
+			desc:     "First",
+			policy:   DuplicateFirst,
+			expected: pair{Letter: "b"},
+		},
+		{
+			desc:     "Positional",
+			policy:   DuplicatePositional,
+			expected: pair{Letter: "b", Letter2: "B"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rows, err := db.Query(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got pair
+			if err := Row(&got, rows, DuplicateColumns(tc.policy)); err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got pair
+		if err := Row(&got, rows, DuplicateColumns(DuplicateError)); ErrorCode(err) != CodeDuplicateColumn {
+			t.Errorf("Expected a duplicate column error, got %v", err)
+		}
+	})
+
+	t.Run("CaseInsensitive", func(t *testing.T) {
+		rows, err := db.Query(`SELECT letter AS "LETTER", upper(letter) AS letter FROM tests WHERE letter = 'b'`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got pair
+		err = Row(&got, rows, CaseInsensitive(), DuplicateColumns(DuplicateError))
+		if ErrorCode(err) != CodeDuplicateColumn {
+			t.Errorf("Expected a duplicate column error, got %v", err)
+		}
+	})
+}
+
+// Identity is embedded by the structs of TestAmbiguousFields.
+type Identity struct {
+	ID int
+}
+
+func TestAmbiguousFields(t *testing.T) {
+	t.Run("Shallowest", func(t *testing.T) {
+		type shallow struct {
+			Identity
+			ID int
+		}
+		mapper, err := NewMapper[shallow]()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path := mapper.mapping["id"].path; path != "ID" {
+			t.Errorf("Expected id to be mapped to ID, got %s", path)
+		}
+	})
+
+	t.Run("Tagged", func(t *testing.T) {
+		type tagged struct {
+			A struct {
+				ID int `db:"id"`
+			}
+			B struct {
+				ID int
+			}
+		}
+		mapper, err := NewMapper[tagged]()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path := mapper.mapping["id"].path; path != "A.ID" {
+			t.Errorf("Expected id to be mapped to A.ID, got %s", path)
+		}
+	})
+
+	t.Run("Ambiguous", func(t *testing.T) {
+		type ambiguous struct {
+			A struct {
+				ID int
+			}
+			B struct {
+				ID int
+			}
+		}
+		mapper, err := NewMapper[ambiguous]()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := db.Query("SELECT weight AS id FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := mapper.ScanAll(rows); ErrorCode(err) != CodeAmbiguousColumn {
+			t.Errorf("Expected an ambiguous column error, got %v", err)
+		}
+	})
+
+	t.Run("Unselected", func(t *testing.T) {
+		type Person struct {
+			ID   int
+			Name string
+		}
+		type Order struct {
+			Total  int
+			Buyer  Person
+			Seller Person
+		}
+		rows, err := db.Query("SELECT weight AS total FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := All[Order](rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 || got[0].Total != 100 {
+			t.Errorf("Expected 3 orders with the first total being 100, got %+v", got)
+		}
+	})
+}
+
+// Node is a self-referential type used by TestRecursiveTypes.
+type Node struct {
+	Letter string
+	Parent *Node
+	Meta   struct {
+		Info struct {
+			Weight int
+		}
+	}
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	rows, err := db.Query("SELECT letter, weight FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := All[Node](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Letter != "A" || got[0].Parent != nil || got[0].Meta.Info.Weight != 100 {
+		t.Errorf("Expected the node A without parent and weight 100, got %+v", got[0])
+	}
+
+	t.Run("MaxDepth", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, weight FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := All[Node](rows, MaxDepth(2)); ErrorCode(err) != CodeUnmappedColumn {
+			t.Errorf("Expected weight to be unmapped, got %v", err)
+		}
+	})
+}
+
+func TestNormalizeColumns(t *testing.T) {
+	expected := []Test{
+		{Letter: "A", Weight: 100},
+		{Letter: "b", Weight: 0},
+		{Letter: "C", Weight: 200},
+	}
+	rows, err := db.Query(`SELECT letter AS "tests.letter", 1, weight AS " weight " FROM tests`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Test
+	if err := Rows(&got, rows, NormalizeColumns(CleanColumn)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	type ordered struct {
+		Letter    string `db:"letter,ord=1"`
+		Lowercase bool   `db:"lower_case,ord=2"`
+		Weight    int    `db:"weight,ord=3"`
+		Exported  bool
+	}
+
+	t.Run("Matching order", func(t *testing.T) {
+		rows, err := db.Query("SELECT * FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []ordered
+		if err := Rows(&got, rows, CheckOrder()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Shifted columns", func(t *testing.T) {
+		rows, err := db.Query("SELECT weight, letter, lower_case, exported FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []ordered
+		if err := Rows(&got, rows, CheckOrder()); err == nil {
+			t.Fatal("Expected an error and got nil")
+		}
+	})
+}
+
+func TestOptionalColumn(t *testing.T) {
+	type optional struct {
+		Letter string
+		NewCol string `db:"new_col,optional"`
+	}
+
+	t.Run("Absent", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter FROM tests WHERE letter=$1", "A")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Strict mode doesn't require optional fields to be populated
+		var got optional
+		if err := Row(&got, rows, Strict()); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := (optional{Letter: "A"}); got != expected {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Present", func(t *testing.T) {
+		rows, err := db.Query("SELECT letter, 'new' AS new_col FROM tests WHERE letter=$1", "A")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got optional
+		if err := Row(&got, rows); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := (optional{Letter: "A", NewCol: "new"}); got != expected {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+}
+
+func TestNoColumns(t *testing.T) {
+	t.Run("Lenient", func(t *testing.T) {
+		rows, err := db.Query("SELECT FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []Test
+		if err := Rows(&got, rows); err != nil {
+			t.Fatal(err)
+		}
+
+		if got != nil {
+			t.Errorf("Expected dest to be untouched, got %v", got)
+		}
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		rows, err := db.Query("SELECT FROM tests")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got Test
+		if err := Row(&got, rows, Strict()); err != ErrNoColumns {
+			t.Errorf("Expected ErrNoColumns, got %v", err)
+		}
+	})
+}
+
+func TestScanCurrent(t *testing.T) {
+	rows, err := db.Query("SELECT letter, weight, lower_case, exported FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got []Test
+	for rows.Next() {
+		var test Test
+		if err := ScanCurrent(&test, rows); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, test)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(records, got) {
+		t.Errorf("Expected %v, got %v", records, got)
+	}
+}
@@ -0,0 +1,256 @@
+package sqantest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Snapshot contains the columns and values of a result set.
+type Snapshot struct {
+	Columns []Column  `json:"columns"`
+	Rows    [][]Value `json:"rows"`
+}
+
+// Column describes a column of a result set.
+type Column struct {
+	Name         string `json:"name"`
+	DatabaseType string `json:"database_type,omitempty"`
+	Nullable     *bool  `json:"nullable,omitempty"`
+}
+
+// Value is a value returned by a driver, it keeps its type when encoded as JSON.
+type Value struct {
+	V driver.Value
+}
+
+// MarshalJSON encodes the value as an object with a single key identifying its type, or null.
+func (v Value) MarshalJSON() ([]byte, error) {
+	var key string
+	switch v.V.(type) {
+	case nil:
+		return []byte("null"), nil
+	case int64:
+		key = "int"
+	case float64:
+		key = "float"
+	case bool:
+		key = "bool"
+	case string:
+		key = "string"
+	case []byte:
+		key = "bytes"
+	case time.Time:
+		key = "time"
+	default:
+		return nil, fmt.Errorf("unsupported driver value type %T", v.V)
+	}
+	return json.Marshal(map[string]driver.Value{key: v.V})
+}
+
+// UnmarshalJSON decodes a value encoded by MarshalJSON.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	if m == nil {
+		v.V = nil
+		return nil
+	}
+	if len(m) != 1 {
+		return errors.New("value must have a single key")
+	}
+
+	for key, raw := range m {
+		var err error
+		switch key {
+		case "int":
+			var i int64
+			err = json.Unmarshal(raw, &i)
+			v.V = i
+		case "float":
+			var f float64
+			err = json.Unmarshal(raw, &f)
+			v.V = f
+		case "bool":
+			var b bool
+			err = json.Unmarshal(raw, &b)
+			v.V = b
+		case "string":
+			var s string
+			err = json.Unmarshal(raw, &s)
+			v.V = s
+		case "bytes":
+			var b []byte
+			err = json.Unmarshal(raw, &b)
+			v.V = b
+		case "time":
+			var t time.Time
+			err = json.Unmarshal(raw, &t)
+			v.V = t
+		default:
+			return fmt.Errorf("unknown value type %q", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record reads all the rows and writes a snapshot of them to w, encoded as JSON.
+//
+// As rows are consumed, it returns a replay of them so they can still be scanned.
+func Record(w io.Writer, rows *sql.Rows) (*sql.Rows, error) {
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	for _, t := range types {
+		column := Column{Name: t.Name(), DatabaseType: t.DatabaseTypeName()}
+		if nullable, ok := t.Nullable(); ok {
+			column.Nullable = &nullable
+		}
+		snapshot.Columns = append(snapshot.Columns, column)
+	}
+
+	values := make([]interface{}, len(types))
+	fields := make([]interface{}, len(types))
+	for i := range values {
+		fields[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		row := make([]Value, len(values))
+		for i, v := range values {
+			row[i] = Value{V: v}
+		}
+		snapshot.Rows = append(snapshot.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot.Replay()
+}
+
+// Replay reads a snapshot written by Record and returns its rows.
+func Replay(r io.Reader) (*sql.Rows, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot.Replay()
+}
+
+// Replay returns the rows of the snapshot, they behave like the ones returned by a database.
+func (s Snapshot) Replay() (*sql.Rows, error) {
+	db := sql.OpenDB(connector{snapshot: s})
+	rows, err := db.Query("")
+	if err != nil {
+		return nil, err
+	}
+	// The rows keep their connection until they are closed
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// connector is a driver.Connector returning the snapshot rows on every query.
+type connector struct {
+	snapshot Snapshot
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return conn(c), nil }
+
+func (c connector) Driver() driver.Driver { return c }
+
+func (c connector) Open(string) (driver.Conn, error) { return conn(c), nil }
+
+type conn connector
+
+func (c conn) Prepare(string) (driver.Stmt, error) { return stmt(c), nil }
+
+func (c conn) Close() error { return nil }
+
+func (c conn) Begin() (driver.Tx, error) { return nil, errors.New("transactions aren't supported") }
+
+type stmt conn
+
+func (s stmt) Close() error { return nil }
+
+func (s stmt) NumInput() int { return -1 }
+
+func (s stmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec isn't supported")
+}
+
+func (s stmt) Query([]driver.Value) (driver.Rows, error) {
+	return &replayRows{snapshot: s.snapshot}, nil
+}
+
+// replayRows implements driver.Rows and the column type interfaces for the snapshot values.
+type replayRows struct {
+	snapshot Snapshot
+	next     int
+}
+
+func (r *replayRows) Columns() []string {
+	columns := make([]string, len(r.snapshot.Columns))
+	for i, c := range r.snapshot.Columns {
+		columns[i] = c.Name
+	}
+	return columns
+}
+
+func (r *replayRows) Close() error { return nil }
+
+func (r *replayRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.snapshot.Rows) {
+		return io.EOF
+	}
+	for i, v := range r.snapshot.Rows[r.next] {
+		dest[i] = v.V
+	}
+	r.next++
+	return nil
+}
+
+func (r *replayRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.snapshot.Columns[index].DatabaseType
+}
+
+func (r *replayRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if n := r.snapshot.Columns[index].Nullable; n != nil {
+		return *n, true
+	}
+	return false, false
+}
+
+// ColumnTypeScanType returns the type of the first non-nil value of the column.
+func (r *replayRows) ColumnTypeScanType(index int) reflect.Type {
+	for _, row := range r.snapshot.Rows {
+		if v := row[index].V; v != nil {
+			return reflect.TypeOf(v)
+		}
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
@@ -0,0 +1,92 @@
+package sqantest
+
+import (
+	"bytes"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const snapshot = `{
+	"columns": [
+		{"name": "id", "database_type": "INT8"},
+		{"name": "name", "database_type": "TEXT", "nullable": true},
+		{"name": "score", "database_type": "FLOAT8"},
+		{"name": "active", "database_type": "BOOL"},
+		{"name": "avatar", "database_type": "BYTEA"},
+		{"name": "created_at", "database_type": "TIMESTAMPTZ"}
+	],
+	"rows": [
+		[{"int": 1}, {"string": "Alice"}, {"float": 1.5}, {"bool": true}, {"bytes": "AQI="}, {"time": "2021-10-10T10:00:00Z"}],
+		[{"int": 2}, null, {"float": 0}, {"bool": false}, null, {"time": "2021-10-11T10:00:00Z"}]
+	]
+}`
+
+func TestRecordReplay(t *testing.T) {
+	expected := [][]interface{}{
+		{int64(1), "Alice", 1.5, true, []byte{1, 2}, time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)},
+		{int64(2), nil, float64(0), false, nil, time.Date(2021, 10, 11, 10, 0, 0, 0, time.UTC)},
+	}
+
+	rows, err := Replay(strings.NewReader(snapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	rows, err = Record(&buf, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := scanAll(t, rows); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Recorded: expected %v, got %v", expected, got)
+	}
+
+	rows, err = Replay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nullable, ok := types[1].Nullable(); !ok || !nullable {
+		t.Errorf("Expected name to be nullable")
+	}
+	if name := types[5].DatabaseTypeName(); name != "TIMESTAMPTZ" {
+		t.Errorf("Expected TIMESTAMPTZ, got %s", name)
+	}
+
+	if got := scanAll(t, rows); !reflect.DeepEqual(expected, got) {
+		t.Errorf("Replayed: expected %v, got %v", expected, got)
+	}
+}
+
+func scanAll(t *testing.T, rows *sql.Rows) [][]interface{} {
+	t.Helper()
+	columns, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values [][]interface{}
+	for rows.Next() {
+		row := make([]interface{}, len(columns))
+		fields := make([]interface{}, len(row))
+		for i := range row {
+			fields[i] = &row[i]
+		}
+		if err := rows.Scan(fields...); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, row)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return values
+}
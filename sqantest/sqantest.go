@@ -0,0 +1,31 @@
+// Package sqantest provides utilities to test code that uses sqan.
+package sqantest
+
+import "testing"
+
+// AssertAllocs fails the test if fn allocates, on average, more than maxAllocsPerRow for each
+// row it scans. fn must return the number of rows scanned.
+//
+// fn is executed once before measuring so the type mappings are cached, then the average
+// number of allocations is taken from several runs. The measurement includes the allocations
+// made by the driver and by database/sql, and the fixed cost of each call is spread across
+// the rows, so it is more accurate with larger result sets.
+//
+// As a reference, scanning into a slice of structs or of scalar values takes about 3
+// allocations per row on top of the ones made by the driver and database/sql when
+// converting values. Destinations with pointer fields take one more for each nil pointer.
+func AssertAllocs(t testing.TB, maxAllocsPerRow float64, fn func() int) {
+	t.Helper()
+
+	rows := fn()
+	if rows == 0 {
+		t.Fatal("sqantest: fn didn't scan any rows")
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		rows = fn()
+	})
+	if perRow := allocs / float64(rows); perRow > maxAllocsPerRow {
+		t.Errorf("sqantest: %.2f allocations per row, expected at most %.2f", perRow, maxAllocsPerRow)
+	}
+}
@@ -0,0 +1,14 @@
+package sqantest
+
+import "testing"
+
+func TestAssertAllocs(t *testing.T) {
+	var sink []int
+	AssertAllocs(t, 1, func() int {
+		sink = make([]int, 0, 100)
+		for i := 0; i < 100; i++ {
+			sink = append(sink, i)
+		}
+		return len(sink)
+	})
+}
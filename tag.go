@@ -0,0 +1,56 @@
+package sqan
+
+import "strings"
+
+// tagOptions are the comma-separated options that follow the column name in a db tag.
+//
+// The grammar of a tag is:
+//
+//	tag    = name *("," option)
+//	option = key ["=" value]
+//
+// The name is the column name, if it's empty the field is named after the naming settings and if
+// it's "-" (with no options) the field is skipped. Keys and values can't contain commas, values may
+// contain "=" and spaces around them are ignored. Options that aren't known are ignored, so tags
+// keep working with future versions that add options. The known ones are:
+//
+//	ord=N          position of the column, checked by CheckOrder
+//	size=N         maximum length of strings and byte slices
+//	min=N, max=N   bounds of numbers, checked in strict mode
+//	nonempty       rejects zero values in strict mode
+//	dbtype=NAME    database type of the column, checked in strict mode
+//	optional       the column may be missing from the result
+//	pk             primary key, used by CollectMapPK
+//	prefix[=P]     prefixes the columns of a struct field's fields with P, or its name followed by "_"
+type tagOptions string
+
+// parseTag splits a db tag into the column name and its options.
+func parseTag(tag string) (string, tagOptions) {
+	if i := strings.IndexByte(tag, ','); i != -1 {
+		return tag[:i], tagOptions(tag[i+1:])
+	}
+	return tag, ""
+}
+
+// Get returns the value of the option key and whether it's present. Options without a value
+// return an empty string.
+func (o tagOptions) Get(key string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		opt := s
+		if i := strings.IndexByte(s, ','); i != -1 {
+			opt, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+
+		name, value := opt, ""
+		if i := strings.IndexByte(opt, '='); i != -1 {
+			name, value = opt[:i], opt[i+1:]
+		}
+		if strings.TrimSpace(name) == key {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,34 @@
+package sqan
+
+import "testing"
+
+func TestParseTag(t *testing.T) {
+	cases := []struct {
+		tag     string
+		name    string
+		key     string
+		value   string
+		present bool
+	}{
+		{tag: "", name: "", key: "pk"},
+		{tag: "id", name: "id", key: "pk"},
+		{tag: "id,pk", name: "id", key: "pk", present: true},
+		{tag: ",optional", name: "", key: "optional", present: true},
+		{tag: "code,size=10,pk", name: "code", key: "size", value: "10", present: true},
+		{tag: "code, size = 10 ", name: "code", key: "size", value: "10", present: true},
+		{tag: "amount,dbtype=NUMERIC", name: "amount", key: "dbtype", value: "NUMERIC", present: true},
+		{tag: "expr,default=a=b", name: "expr", key: "default", value: "a=b", present: true},
+		{tag: "id,pkey", name: "id", key: "pk"},
+	}
+
+	for _, tc := range cases {
+		name, opts := parseTag(tc.tag)
+		if name != tc.name {
+			t.Errorf("%q: expected name %q, got %q", tc.tag, tc.name, name)
+		}
+		value, ok := opts.Get(tc.key)
+		if ok != tc.present || value != tc.value {
+			t.Errorf("%q: expected option %s=%q (%v), got %q (%v)", tc.tag, tc.key, tc.value, tc.present, value, ok)
+		}
+	}
+}
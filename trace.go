@@ -0,0 +1,70 @@
+package sqan
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// Trace writes to w how the columns of the first n rows scanned into structs are decoded: the database
+// type of each column, the type of the value returned by the driver and the field it's scanned into.
+// It's meant to debug drivers returning unexpected types.
+//
+// Tracing scans the rows twice, it shouldn't be used in production.
+func Trace(w io.Writer, n int) Option {
+	return func(o *options) {
+		o.traceWriter = w
+		o.traceRows = n
+	}
+}
+
+// traceProbe is a scanner that records the value it receives.
+type traceProbe struct {
+	src interface{}
+}
+
+func (p *traceProbe) Scan(src interface{}) error {
+	p.src = src
+	return nil
+}
+
+// trace writes the decoding of the current row if it's one of the rows traced.
+func (o *options) trace(rows *sql.Rows, columns []string, columnFields []*field) error {
+	if o.traceWriter == nil || o.traced >= o.traceRows {
+		return nil
+	}
+	o.traced++
+
+	probes := make([]traceProbe, len(columns))
+	fields := make([]interface{}, len(columns))
+	for i := range probes {
+		fields[i] = &probes[i]
+	}
+	if err := rows.Scan(fields...); err != nil {
+		return err
+	}
+
+	// Not all drivers report the types
+	dbTypes := make([]string, len(columns))
+	if types, err := rows.ColumnTypes(); err == nil {
+		for i, t := range types {
+			dbTypes[i] = t.DatabaseTypeName()
+		}
+	}
+
+	prefix := ""
+	if o.tag != "" {
+		prefix = o.tag + ": "
+	}
+	for i, c := range columns {
+		dest := "skipped"
+		if f := columnFields[i]; f != nil {
+			dest = fmt.Sprintf("%s %s", f.path, f.typ)
+		}
+		_, err := fmt.Fprintf(o.traceWriter, "%srow %d: column %q (%s): %T -> %s\n", prefix, o.traced, c, dbTypes[i], probes[i].src, dest)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package sqan
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	rows, err := db.Query("SELECT letter, weight FROM tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		got []Test
+		buf bytes.Buffer
+	)
+	if err := Rows(&got, rows, Trace(&buf, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `row 1: column "letter" (TEXT): string -> Letter string
+row 1: column "weight" (INT4): int64 -> Weight int
+`
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}